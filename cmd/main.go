@@ -1,26 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"os"
+	"os/signal"
 
-	"sort_utility/internal/app"
+	"github.com/rzmsq/sort_utility/internal/app"
 )
 
-func errorExit(err error) {
-	_, err = fmt.Fprintln(os.Stderr, err)
-	if err != nil {
-		panic(err)
-	}
-	os.Exit(1)
-}
-
 func main() {
-	if len(os.Args) < 2 {
-		errorExit(fmt.Errorf("usage: go run main.go <file path>"))
-	}
-	err := app.RunApp(os.Args...)
-	if err != nil {
-		errorExit(err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := app.Run(ctx, os.Stdin, os.Stdout, os.Stderr, os.Args[1:], os.Getenv)
+	stop()
+	os.Exit(code)
 }
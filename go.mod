@@ -1,3 +1,3 @@
-module sort_utility
+module github.com/rzmsq/sort_utility
 
 go 1.25
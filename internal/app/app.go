@@ -1,45 +1,147 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 
-	p "sort_utility/internal/args"
-	f "sort_utility/internal/file"
+	p "github.com/rzmsq/sort_utility/internal/args"
+	f "github.com/rzmsq/sort_utility/internal/file"
+	"github.com/rzmsq/sort_utility/internal/messages"
+	"github.com/rzmsq/sort_utility/sortutil"
 )
 
 // RunApp start app
 func RunApp(args ...string) error {
-	filePath, options, err := p.ParseArgs(args[1:])
-	if err != nil {
-		return fmt.Errorf("sort: %s", err)
-	}
-	file, err := f.OpenFile(filePath)
+	return Execute(context.Background(), os.Stdin, os.Stdout, os.Stderr, args[1:], os.Getenv)
+}
+
+// Run runs the command with the arguments that follow the program name and returns the exit code, see ExitCode
+// Errors are written to stderr after the program name; a usage error is followed by a pointer to --help
+// Messages are in the language of --lang, or else of the locale getenv reads, see messages.FromEnvironment
+// Run does not panic, a panic is reported as an internal error
+func Run(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args []string, getenv func(string) string) (code int) {
+	language := messages.FromEnvironment(getenv)
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprint(stderr, language.Sprintf("%s: internal error: %v\n", p.Program, r))
+			code = ExitTrouble
+		}
+	}()
+
+	language, err := execute(ctx, stdin, stdout, stderr, args, getenv)
 	if err != nil {
-		if errors.Is(err, p.ErrFileNotFound) {
-			return fmt.Errorf("sort: %w", err)
+		fmt.Fprintf(stderr, "%s: %s\n", p.Program, language.Error(err))
+
+		var usageErr *UsageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprint(stderr, language.Sprintf("Try '%s --help' for more information.\n", p.Program))
 		}
-		return err
 	}
-	defer func(file *os.File) {
-		err = file.Close()
+	return ExitCode(err)
+}
+
+// Execute runs the command with the arguments that follow the program name
+// Without a file or with the file "-" stdin is read; --help and --version write their text to stdout
+// Errors are a *UsageError, *IOError or *DataError, or a *DisorderError when -c finds lines out of order
+// Messages and the text of errors are in the language of --lang, or else of the locale getenv reads
+// With --debug the locale and warnings are written to stderr
+// Once ctx is done reading, sorting and writing stop and the returned error wraps the context error
+func Execute(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args []string, getenv func(string) string) error {
+	language, err := execute(ctx, stdin, stdout, stderr, args, getenv)
+	return messages.In(language, err)
+}
+
+// execute works as Execute and also returns the language of messages, in which the error is to be written
+func execute(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args []string, getenv func(string) string) (language messages.Language, err error) {
+	filePath, options, err := p.ParseArgs(args)
+	langFlag := options.Language != ""
+	language = messages.FromEnvironment(getenv)
+	if langFlag {
+		language = options.MessageLanguage()
+	}
+	options.Language = string(language)
+
+	switch {
+	case errors.Is(err, p.ErrHelp):
+		return language, p.WriteHelp(stdout, language)
+	case errors.Is(err, p.ErrVersion):
+		return language, writeVersion(stdout, language)
+	case errors.Is(err, p.ErrFileNotFound):
+		return language, &IOError{Op: "cannot read", Err: p.ErrFileNotFound}
+	case err != nil:
+		return language, &UsageError{Err: err}
+	}
+
+	input, inputPath := stdin, "-"
+	if filePath != "" && filePath != "-" {
+		file, err := os.Open(filePath)
 		if err != nil {
-			panic(err)
+			return language, ioError("cannot read", filePath, err)
 		}
-	}(file)
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = ioError("close failed", filePath, closeErr)
+			}
+		}()
+		input, inputPath = file, filePath
+	}
 
-	sortedLines, err := f.SortFile(file, options)
+	sorter, err := sortutil.New(*options)
 	if err != nil {
-		return err
+		return language, &UsageError{Err: err}
+	}
+
+	warn := func(message string) {
+		fmt.Fprintf(stderr, "%s: %s\n", p.Program, message)
+	}
+	if options.Debug {
+		debugLocale(warn, language, langFlag, getenv)
 	}
 
-	for _, line := range sortedLines {
-		_, err = os.Stdout.Write([]byte(line + "\n"))
+	output := &outputWriter{writer: stdout}
+	if options.IsSorted {
+		disorder, err := sorter.FirstDisorder(ctx, input)
 		if err != nil {
-			return err
+			return language, inputError(inputPath, err)
 		}
+		if disorder != nil {
+			return language, &DisorderError{Path: inputPath, Line: disorder.Line, Text: disorder.Text}
+		}
+		return language, nil
 	}
 
-	return nil
+	sortInput := sorter.SortContext
+	if options.Debug {
+		sortInput = func(ctx context.Context, r io.Reader, w io.Writer) error {
+			return f.Debug(ctx, r, w, warn, options)
+		}
+	}
+	if err := sortInput(ctx, input, output); err != nil {
+		if output.err != nil {
+			return language, ioError("write failed", "standard output", output.err)
+		}
+		return language, inputError(inputPath, err)
+	}
+	return language, nil
+}
+
+// debugLocale reports the locale and the language of messages for --debug
+// langFlag tells whether the language was set with --lang rather than taken from the locale
+func debugLocale(warn func(message string), language messages.Language, langFlag bool, getenv func(string) string) {
+	warn(language.Text("text ordering performed using simple byte comparison"))
+
+	if variable, locale := messages.Locale(getenv); locale != "" {
+		warn(language.Sprintf("using the %s locale from %s", locale, variable))
+	} else {
+		warn(language.Text("no locale is set in LC_ALL, LC_MESSAGES or LANG"))
+	}
+
+	if langFlag {
+		warn(language.Sprintf("messages in %s, set with --lang", language.Text(language.Name())))
+	} else {
+		warn(language.Sprintf("messages in %s", language.Text(language.Name())))
+	}
 }
@@ -1,7 +1,12 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -65,3 +70,268 @@ func TestRunApp(t *testing.T) {
 		})
 	}
 }
+
+// environment returns a getenv that reads vars instead of the environment of the process
+func environment(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func TestRun(t *testing.T) {
+	getenv := environment(map[string]string{"LC_ALL": "C"})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		args           []string
+		stdin          string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "sort stdin",
+			ctx:            context.Background(),
+			args:           []string{"-n", "-"},
+			stdin:          "10\n9\n",
+			expectedStdout: "9\n10\n",
+		},
+		{
+			name:  "check mode",
+			ctx:   context.Background(),
+			args:  []string{"-c"},
+			stdin: "a\nb\n",
+		},
+		{
+			name:           "check mode disorder",
+			ctx:            context.Background(),
+			args:           []string{"-c"},
+			stdin:          "a\nc\nb\n",
+			expectedCode:   ExitDisorder,
+			expectedStderr: "sort_utility: -:3: disorder: b\n",
+		},
+		{
+			name:           "check mode disorder after the header",
+			ctx:            context.Background(),
+			args:           []string{"-c", "--header=1", "-n"},
+			stdin:          "N\n2\n1\n",
+			expectedCode:   ExitDisorder,
+			expectedStderr: "sort_utility: -:3: disorder: 1\n",
+		},
+		{
+			name:           "check mode disorder in Russian",
+			ctx:            context.Background(),
+			args:           []string{"--lang=ru", "-c"},
+			stdin:          "b\na\n",
+			expectedCode:   ExitDisorder,
+			expectedStderr: "sort_utility: -:2: нарушен порядок: a\n",
+		},
+		{
+			name:           "conflicting options",
+			ctx:            context.Background(),
+			args:           []string{"--csv", "--jsonl"},
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: conflicting input formats: only one of --csv, --tsv, --jsonl, --json can be used\nTry 'sort_utility --help' for more information.\n",
+		},
+		{
+			name:           "missing file",
+			ctx:            context.Background(),
+			args:           []string{"missing.txt"},
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: cannot read: missing.txt: no such file or directory\n",
+		},
+		{
+			name:           "invalid data",
+			ctx:            context.Background(),
+			args:           []string{"--date", "--date-invalid=error"},
+			stdin:          "soon\n",
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: -: invalid date: line 1: soon\n",
+		},
+		{
+			name:           "diagnostic in Russian",
+			ctx:            context.Background(),
+			args:           []string{"--lang", "ru", "--date", "--date-invalid=error"},
+			stdin:          "soon\n",
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: -: некорректная дата: строка 1: soon\n",
+		},
+		{
+			name:           "unknown language",
+			ctx:            context.Background(),
+			args:           []string{"--lang=de"},
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: invalid argument 'de' for '--lang'\nTry 'sort_utility --help' for more information.\n",
+		},
+		{
+			name:           "invalid option",
+			ctx:            context.Background(),
+			args:           []string{"-x"},
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: invalid option -- 'x'\nTry 'sort_utility --help' for more information.\n",
+		},
+		{
+			name:           "empty argument",
+			ctx:            context.Background(),
+			args:           []string{"-n", ""},
+			stdin:          "b\na\n",
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: cannot read: '': no such file or directory\n",
+		},
+		{
+			name:           "canceled",
+			ctx:            canceled,
+			args:           []string{},
+			stdin:          "b\na\n",
+			expectedCode:   ExitTrouble,
+			expectedStderr: "sort_utility: context canceled\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			code := Run(tt.ctx, strings.NewReader(tt.stdin), &stdout, &stderr, tt.args, getenv)
+
+			if code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d", tt.expectedCode, code)
+			}
+			if stdout.String() != tt.expectedStdout {
+				t.Errorf("expected stdout %q, got %q", tt.expectedStdout, stdout.String())
+			}
+			if stderr.String() != tt.expectedStderr {
+				t.Errorf("expected stderr %q, got %q", tt.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunHelpAndVersion(t *testing.T) {
+	getenv := environment(map[string]string{"LC_ALL": "C"})
+
+	tests := []struct {
+		args   []string
+		prefix string
+	}{
+		{[]string{"--help"}, "Usage: sort_utility [OPTION]... [FILE]\n"},
+		{[]string{"-n", "--help", "missing.txt"}, "Usage: sort_utility [OPTION]... [FILE]\n"},
+		{[]string{"--lang=ru", "--help"}, "Использование: sort_utility [ОПЦИЯ]... [ФАЙЛ]\n"},
+		{[]string{"--version"}, "sort_utility v"},
+		{[]string{"--vers"}, "sort_utility v"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr strings.Builder
+		code := Run(context.Background(), strings.NewReader(""), &stdout, &stderr, tt.args, getenv)
+
+		if code != 0 || stderr.Len() != 0 {
+			t.Errorf("Run(%q) = %d with stderr %q, expected 0", tt.args, code, stderr.String())
+		}
+		if !strings.HasPrefix(stdout.String(), tt.prefix) {
+			t.Errorf("Run(%q) stdout = %q, expected prefix %q", tt.args, stdout.String(), tt.prefix)
+		}
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestExecuteErrors(t *testing.T) {
+	getenv := environment(map[string]string{"LC_ALL": "C"})
+
+	var usageErr *UsageError
+	var ioErr *IOError
+	var dataErr *DataError
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdout   io.Writer
+		target   any
+		expected string
+	}{
+		{"usage", []string{"-n", "-M"}, io.Discard, &usageErr, ""},
+		{"missing file", []string{"missing.txt"}, io.Discard, &ioErr, "cannot read: missing.txt: no such file or directory"},
+		{"write", []string{}, failingWriter{}, &ioErr, "write failed: standard output: disk full"},
+		{"data", []string{"--jsonl", "-k", ".a"}, io.Discard, &dataErr, "-: invalid JSON: line 1: invalid character '}' looking for beginning of value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Execute(context.Background(), strings.NewReader("}\n"), tt.stdout, io.Discard, tt.args, getenv)
+			if !errors.As(err, tt.target) {
+				t.Fatalf("expected %T, got %T: %v", tt.target, err, err)
+			}
+			if tt.expected != "" && err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+			if ExitCode(err) != ExitTrouble {
+				t.Errorf("expected exit code %d, got %d", ExitTrouble, ExitCode(err))
+			}
+		})
+	}
+
+	err := Execute(context.Background(), strings.NewReader(""), io.Discard, io.Discard, []string{"missing.txt"}, getenv)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestRunLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		lcAll          string
+		lcMessages     string
+		lang           string
+		args           []string
+		expectedStderr string
+	}{
+		{"LANG", "", "", "ru_RU.UTF-8", []string{"-x"}, "sort_utility: недопустимая опция -- 'x'\nПо команде 'sort_utility --help' можно получить дополнительную информацию.\n"},
+		{"LC_MESSAGES over LANG", "", "en_US.UTF-8", "ru_RU.UTF-8", []string{"missing.txt"}, "sort_utility: cannot read: missing.txt: no such file or directory\n"},
+		{"LC_ALL over LC_MESSAGES", "ru_RU.UTF-8", "en_US.UTF-8", "", []string{"missing.txt"}, "sort_utility: не удается прочитать: missing.txt: нет такого файла или каталога\n"},
+		{"unknown locale", "", "", "de_DE.UTF-8", []string{"--reverse=yes"}, "sort_utility: option '--reverse' doesn't allow an argument\nTry 'sort_utility --help' for more information.\n"},
+		{"--lang over locale", "", "", "ru_RU.UTF-8", []string{"--lang=en", "-c", "-r"}, "sort_utility: check mode (-c) cannot be used with -r or -u\nTry 'sort_utility --help' for more information.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := environment(map[string]string{"LC_ALL": tt.lcAll, "LC_MESSAGES": tt.lcMessages, "LANG": tt.lang})
+
+			var stdout, stderr strings.Builder
+			Run(context.Background(), strings.NewReader(""), &stdout, &stderr, tt.args, getenv)
+
+			if stderr.String() != tt.expectedStderr {
+				t.Errorf("expected stderr %q, got %q", tt.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunDebug(t *testing.T) {
+	getenv := environment(map[string]string{"LANG": "ru_RU.UTF-8"})
+
+	var stdout, stderr strings.Builder
+	code := Run(context.Background(), strings.NewReader("b x\na y\n"), &stdout, &stderr, []string{"--lang=en", "--debug", "-n", "-k", "2"}, getenv)
+
+	if code != ExitOK {
+		t.Errorf("expected exit code %d, got %d", ExitOK, code)
+	}
+	if expected := "b x\n  _\na y\n  _\n"; stdout.String() != expected {
+		t.Errorf("expected stdout %q, got %q", expected, stdout.String())
+	}
+	expected := "sort_utility: text ordering performed using simple byte comparison\n" +
+		"sort_utility: using the ru_RU.UTF-8 locale from LANG\n" +
+		"sort_utility: messages in English, set with --lang\n" +
+		"sort_utility: key 1 has no valid numeric value in any line; the values are compared as text\n"
+	if stderr.String() != expected {
+		t.Errorf("expected stderr %q, got %q", expected, stderr.String())
+	}
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	f "github.com/rzmsq/sort_utility/internal/file"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// Exit codes of the command, the same as GNU sort
+const (
+	ExitOK       = 0 // Sorted, or -c found the input in order
+	ExitDisorder = 1 // -c found lines out of order
+	ExitTrouble  = 2 // Usage, I/O or data error
+)
+
+// ErrDisorder is wrapped by the error Execute returns when -c finds lines out of order
+var ErrDisorder = errors.New("disorder")
+
+// DisorderError is the first line -c finds out of order
+type DisorderError struct {
+	Path string // File name, "-" for standard input
+	Line int    // Line number, counting from 1
+	Text string // The line
+}
+
+func (e *DisorderError) Error() string {
+	return e.Localize(messages.English)
+}
+
+// Localize writes the error in language, see messages.Localizer
+func (e *DisorderError) Localize(language messages.Language) string {
+	return fmt.Sprintf("%s:%d: %s: %s", e.Path, e.Line, language.Error(ErrDisorder), e.Text)
+}
+
+func (e *DisorderError) Unwrap() error {
+	return ErrDisorder
+}
+
+// UsageError is a mistake in the command line or a combination of options that cannot be used together
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+// Localize writes the error in language, see messages.Localizer
+func (e *UsageError) Localize(language messages.Language) string {
+	return language.Error(e.Err)
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// IOError is an input or output that cannot be opened, read, written or closed
+type IOError struct {
+	Op   string // What failed: "cannot read", "read failed", "write failed" or "close failed"
+	Path string // File name, "-" for standard input, empty for an empty operand, or "standard output"
+	Err  error
+}
+
+func (e *IOError) Error() string {
+	return e.Localize(messages.English)
+}
+
+// Localize writes the error in language, see messages.Localizer
+func (e *IOError) Localize(language messages.Language) string {
+	path := e.Path
+	if path == "" {
+		path = "''"
+	}
+	return language.Text(e.Op) + ": " + language.Text(path) + ": " + language.Error(e.Err)
+}
+
+func (e *IOError) Unwrap() error {
+	return e.Err
+}
+
+// DataError is input that cannot be sorted with the given options, such as an invalid date with --date-invalid=error
+type DataError struct {
+	Path string // File name, "-" for standard input
+	Err  error
+}
+
+// Error names the file unless the message of Err already starts with it, as "FILE:LINE: ..." does
+func (e *DataError) Error() string {
+	return e.Localize(messages.English)
+}
+
+// Localize writes the error in language, see messages.Localizer
+func (e *DataError) Localize(language messages.Language) string {
+	message := language.Error(e.Err)
+	if strings.HasPrefix(message, e.Path+":") {
+		return message
+	}
+	return e.Path + ": " + message
+}
+
+func (e *DataError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for an error returned by Execute
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrDisorder):
+		return ExitDisorder
+	}
+	return ExitTrouble
+}
+
+// inputError classifies an error met while reading and sorting the input named path
+func inputError(path string, err error) error {
+	var parseErr *csv.ParseError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, f.ErrInvalidDate), errors.Is(err, f.ErrInvalidJSON), errors.Is(err, f.ErrNoMatch),
+		errors.Is(err, f.ErrLineTooLong), errors.Is(err, p.ErrUnknownColumn), errors.As(err, &parseErr):
+		return &DataError{Path: path, Err: err}
+	}
+	return ioError("read failed", path, err)
+}
+
+// ioError makes an IOError, dropping the operation and path an *fs.PathError repeats
+func ioError(op, path string, err error) *IOError {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return &IOError{Op: op, Path: path, Err: err}
+}
+
+// outputWriter remembers the first error of the writer it wraps,
+// so a failed write is not reported as a failed read
+type outputWriter struct {
+	writer io.Writer
+	err    error
+}
+
+func (w *outputWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+	"github.com/rzmsq/sort_utility/sortutil"
+)
+
+// writeVersion writes the --version text: the module version and the VCS revision it was built from
+// A binary built inside the repository has no module version and reports the sortutil API version
+func writeVersion(w io.Writer, language messages.Language) error {
+	version := "v" + sortutil.Version
+	var revision string
+	modified := false
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Version != "" && info.Main.Version != "(devel)" {
+			version = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+	}
+
+	text := fmt.Sprintf("%s %s\n", p.Program, version)
+	switch {
+	case revision != "" && modified:
+		text += language.Sprintf("revision %s, modified\n", revision)
+	case revision != "":
+		text += language.Sprintf("revision %s\n", revision)
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
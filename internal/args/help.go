@@ -0,0 +1,56 @@
+package args
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// Program is the name of the command in --help and diagnostics
+const Program = "sort_utility"
+
+// Text of --help around the options, see messages.Language.Sprintf
+const (
+	helpUsage = "Usage: %s [OPTION]... [FILE]\n" +
+		"Write the sorted lines of FILE to standard output.\n" +
+		"With no FILE, or when FILE is -, read standard input.\n\n"
+	helpKeys = "KEYDEF is N[:TYPE[=ARG]], where N is a column number, a negative number from the end,\n" +
+		"a CSV column name or a JSON path, or F1[,F2][OPTS] with OPTS among b, h, M, n and r\n"
+)
+
+// WriteHelp writes the --help text in language, with one line for every option of the command line
+func WriteHelp(w io.Writer, language messages.Language) error {
+	names := make([]string, len(optionTable))
+	width := 0
+	for i, opt := range optionTable {
+		names[i] = helpName(opt)
+		width = max(width, len(names[i]))
+	}
+
+	out := bufio.NewWriter(w)
+	out.WriteString(language.Sprintf(helpUsage, Program))
+	for i, opt := range optionTable {
+		out.WriteString("  " + names[i] + strings.Repeat(" ", width-len(names[i])+2) + language.Text(opt.help) + "\n")
+	}
+	out.WriteString("\n" + language.Text(helpKeys))
+	return out.Flush()
+}
+
+// helpName returns the names of an option as shown in --help: "-k, --key=KEYDEF"
+func helpName(opt option) string {
+	name := "    "
+	if opt.short != 0 {
+		name = "-" + string(opt.short) + ", "
+	}
+	name += "--" + opt.long
+
+	switch opt.arg {
+	case requiredArgument:
+		name += "=" + opt.value
+	case optionalArgument:
+		name += "[=" + opt.value + "]"
+	}
+	return name
+}
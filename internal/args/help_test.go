@@ -0,0 +1,64 @@
+package args
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+func TestWriteHelp(t *testing.T) {
+	var help strings.Builder
+	if err := WriteHelp(&help, messages.English); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := help.String()
+	if !strings.HasPrefix(text, "Usage: "+Program+" [OPTION]... [FILE]\n") {
+		t.Errorf("help does not start with the usage line: %q", text)
+	}
+	for _, opt := range optionTable {
+		if opt.help == "" {
+			t.Errorf("option --%s has no help", opt.long)
+		}
+		if !strings.Contains(text, helpName(opt)+" ") {
+			t.Errorf("help does not describe --%s", opt.long)
+		}
+	}
+}
+
+func TestWriteHelpRussian(t *testing.T) {
+	for _, text := range []string{helpUsage, helpKeys} {
+		if messages.Russian.Text(text) == text {
+			t.Errorf("no Russian translation of %q", text)
+		}
+	}
+	for _, opt := range optionTable {
+		if messages.Russian.Text(opt.help) == opt.help {
+			t.Errorf("no Russian translation of the help of --%s", opt.long)
+		}
+	}
+}
+
+func TestHelpName(t *testing.T) {
+	tests := []struct {
+		long     string
+		expected string
+	}{
+		{"numeric-sort", "-n, --numeric-sort"},
+		{"key", "-k, --key=KEYDEF"},
+		{"csv", "    --csv"},
+		{"header", "    --header=N"},
+		{"date", "    --date[=LAYOUT]"},
+	}
+
+	for _, tt := range tests {
+		opt, err := longOption(tt.long)
+		if err != nil {
+			t.Fatalf("longOption(%q): %v", tt.long, err)
+		}
+		if got := helpName(opt); got != tt.expected {
+			t.Errorf("helpName(--%s) = %q, expected %q", tt.long, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package args
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// ErrDuplicateKeyType is returned when a key type name is registered twice
+var ErrDuplicateKeyType = messages.New("key type already registered")
+
+// KeyType orders the values of a sort key
+type KeyType interface {
+	// Parse converts the key text to a value; an error marks the text as invalid
+	// Invalid values go after all valid ones and are compared as text among themselves
+	Parse(key string) (any, error)
+	// Compare returns -1, 0 or +1 as a is less than, equal to or greater than b
+	Compare(a, b any) int
+}
+
+// KeyTypeFactory makes the key type for ARG in -k N:TYPE=ARG, which is empty without "="
+// The options are the global ones, after all flags are parsed
+type KeyTypeFactory func(arg string, options *KeySort) (KeyType, error)
+
+var (
+	keyTypesMu sync.RWMutex
+	keyTypes   = map[string]KeyTypeFactory{}
+)
+
+// RegisterKeyType makes a key type available as -k N:NAME[=ARG] and --sort=NAME[=ARG]
+// The built-in types lexical, numeric, month, human, date, ip, semver, natural and duration are registered by the file package
+func RegisterKeyType(name string, factory KeyTypeFactory) error {
+	if name == "" || strings.ContainsAny(name, ":=,") {
+		return messages.Errorf("%w: key type name %q", ErrInvalidArgument, name)
+	}
+
+	keyTypesMu.Lock()
+	defer keyTypesMu.Unlock()
+
+	if _, ok := keyTypes[name]; ok {
+		return messages.Errorf("%w: %s", ErrDuplicateKeyType, name)
+	}
+	keyTypes[name] = factory
+	return nil
+}
+
+// LookupKeyType returns the factory registered under name
+func LookupKeyType(name string) (KeyTypeFactory, bool) {
+	keyTypesMu.RLock()
+	defer keyTypesMu.RUnlock()
+
+	factory, ok := keyTypes[name]
+	return factory, ok
+}
+
+// KeyTypeName returns the registered name and the argument of the key type
+// A key without a type is lexical
+func (k Key) KeyTypeName() (string, string) {
+	switch {
+	case k.Type != "":
+		return k.Type, k.TypeArg
+	case k.Numeric:
+		return "numeric", ""
+	case k.Month:
+		return "month", ""
+	case k.HumanNumeric:
+		return "human", ""
+	case k.Date:
+		return "date", k.DateLayout
+	case k.IP:
+		return "ip", ""
+	case k.SemVer:
+		if k.SemVerV {
+			return "semver", "v"
+		}
+		return "semver", ""
+	case k.Natural:
+		return "natural", ""
+	case k.Duration:
+		return "duration", ""
+	}
+	return "lexical", ""
+}
+
+// ParseKeyType returns a key of column 1 with the type TYPE[=ARG], as written after ":" in -k
+// An empty type is lexical
+func ParseKeyType(keyType string) (Key, error) {
+	key := Key{ColumnNumber: 1}
+	if keyType == "" {
+		return key, nil
+	}
+	if err := parseKeyType(&key, keyType); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+// validateKeyType checks that a registered key type accepts its argument
+func validateKeyType(name, arg string, options *KeySort) error {
+	factory, ok := LookupKeyType(name)
+	if !ok {
+		return messages.Errorf("%w: %s", ErrUnknownKeyType, name)
+	}
+	if _, err := factory(arg, options); err != nil {
+		return messages.Errorf("%w: %s=%s: %v", ErrInvalidArgument, name, arg, err)
+	}
+	return nil
+}
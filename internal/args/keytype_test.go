@@ -0,0 +1,101 @@
+package args
+
+import (
+	"errors"
+	"testing"
+)
+
+// reverseType orders keys in reverse lexical order; its argument must be empty
+type reverseType struct{}
+
+func (reverseType) Parse(key string) (any, error) {
+	return key, nil
+}
+
+func (reverseType) Compare(a, b any) int {
+	switch {
+	case a.(string) > b.(string):
+		return -1
+	case a.(string) < b.(string):
+		return 1
+	}
+	return 0
+}
+
+func TestRegisterKeyType(t *testing.T) {
+	factory := func(arg string, _ *KeySort) (KeyType, error) {
+		if arg != "" {
+			return nil, errors.New("no argument expected")
+		}
+		return reverseType{}, nil
+	}
+	if err := RegisterKeyType("reverse-text", factory); err != nil {
+		t.Fatalf("RegisterKeyType() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Key
+		expectError bool
+	}{
+		{"key type", []string{"-k", "2:reverse-text", "test.txt"}, Key{ColumnNumber: 2, Type: "reverse-text"}, false},
+		{"global sort type", []string{"--sort=reverse-text", "test.txt"}, Key{Type: "reverse-text"}, false},
+		{"global built-in sort type", []string{"--sort=n", "test.txt"}, Key{Numeric: true}, false},
+		{"rejected argument", []string{"-k", "2:reverse-text=x", "test.txt"}, Key{}, true},
+		{"conflicting sort types", []string{"--sort=reverse-text", "-n", "test.txt"}, Key{}, true},
+		{"unknown sort type", []string{"--sort=colour", "test.txt"}, Key{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, opts, err := ParseArgs(tt.args)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			key := Key{Numeric: opts.Numeric, Type: opts.Type}
+			if len(opts.Keys) > 0 {
+				key = opts.Keys[0]
+			}
+			if key != tt.expected {
+				t.Errorf("expected key %+v, got %+v", tt.expected, key)
+			}
+		})
+	}
+
+	if err := RegisterKeyType("reverse-text", factory); !errors.Is(err, ErrDuplicateKeyType) {
+		t.Errorf("expected ErrDuplicateKeyType, got %v", err)
+	}
+	if err := RegisterKeyType("a:b", factory); err == nil {
+		t.Errorf("expected error for a name containing ':'")
+	}
+}
+
+func TestKeyTypeName(t *testing.T) {
+	tests := []struct {
+		key          Key
+		expectedName string
+		expectedArg  string
+	}{
+		{Key{}, "lexical", ""},
+		{Key{Numeric: true}, "numeric", ""},
+		{Key{Date: true, DateLayout: "2006"}, "date", "2006"},
+		{Key{SemVer: true, SemVerV: true}, "semver", "v"},
+		{Key{Type: "custom", TypeArg: "x"}, "custom", "x"},
+	}
+
+	for _, tt := range tests {
+		name, arg := tt.key.KeyTypeName()
+		if name != tt.expectedName || arg != tt.expectedArg {
+			t.Errorf("KeyTypeName(%+v) = %q, %q, expected %q, %q", tt.key, name, arg, tt.expectedName, tt.expectedArg)
+		}
+	}
+}
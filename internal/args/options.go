@@ -0,0 +1,313 @@
+package args
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// argKind says whether an option takes an argument
+type argKind int
+
+const (
+	noArgument       argKind = iota // --name
+	requiredArgument                // --name=VALUE, --name VALUE, -xVALUE or -x VALUE
+	optionalArgument                // --name or --name=VALUE
+)
+
+// option is one command-line option
+type option struct {
+	short rune    // Single-letter name, 0 if there is none
+	long  string  // Long name without "--"
+	arg   argKind // Whether the option takes an argument
+	value string  // Name of the argument in --help
+	help  string  // Description in --help
+	apply func(value string, optionSort *KeySort) error
+}
+
+// optionError is a GNU-style diagnostic for a mistake in the command line
+type optionError struct {
+	err    error  // Sentinel error the diagnostic belongs to
+	format string // Text of the diagnostic, see messages.Language.Sprintf
+	args   []any
+}
+
+func (e *optionError) Error() string {
+	return e.Localize(messages.English)
+}
+
+func (e *optionError) Localize(language messages.Language) string {
+	return language.Sprintf(e.format, e.args...)
+}
+
+func (e *optionError) Unwrap() error {
+	return e.err
+}
+
+// optionTable is the table of all command-line options
+var optionTable = []option{
+	{'b', "ignore-leading-blanks", noArgument, "", "ignore leading blanks", set(func(o *KeySort) { o.SkipBlanks = true })},
+	{'c', "check", noArgument, "", "check whether the input is sorted, do not sort", set(func(o *KeySort) { o.IsSorted = true })},
+	{'h', "human-numeric-sort", noArgument, "", "compare human readable sizes (2K, 1G)", set(func(o *KeySort) { o.HumanNumeric = true })},
+	{'k', "key", requiredArgument, "KEYDEF", "sort by a key: N[:TYPE[=ARG]] or F1[,F2][OPTS]; may be repeated", addKey},
+	{'M', "month-sort", noArgument, "", "compare month names (JAN < ... < DEC)", set(func(o *KeySort) { o.Month = true })},
+	{'n', "numeric-sort", noArgument, "", "compare numeric values", set(func(o *KeySort) { o.Numeric = true })},
+	{'r', "reverse", noArgument, "", "reverse the result of comparisons", set(func(o *KeySort) { o.Reverse = true })},
+	{'u', "unique", noArgument, "", "output only the first of equal lines", set(func(o *KeySort) { o.Unique = true })},
+	{'z', "zero-terminated", noArgument, "", "lines end with NUL, not newline", set(func(o *KeySort) { o.ZeroTerminated = true })},
+	{0, "sort", requiredArgument, "TYPE[=ARG]", "compare with the key type TYPE: lexical, numeric, month, human, date, ip, semver, natural, duration", func(value string, o *KeySort) error {
+		var key Key
+		if err := parseKeyType(&key, value); err != nil {
+			return err
+		}
+		setSortType(o, key)
+		return nil
+	}},
+	{0, "date", optionalArgument, "LAYOUT", "compare dates, in the Go time LAYOUT or detected", func(value string, o *KeySort) error {
+		o.Date = true
+		o.DateLayout = value
+		return nil
+	}},
+	{0, "date-tz", requiredArgument, "ZONE", "time zone of dates without an offset", func(value string, o *KeySort) error {
+		location, err := time.LoadLocation(value)
+		if err != nil || value == "" {
+			return invalidArgument(value)
+		}
+		o.DateZone = location
+		return nil
+	}},
+	{0, "date-invalid", requiredArgument, "WHERE", "put unparsable dates first, last or report an error", placement(func(o *KeySort, value string) { o.DateInvalid = value }, InvalidFirst, InvalidLast, InvalidError)},
+	{0, "ip", noArgument, "", "compare IP addresses and CIDR prefixes", set(func(o *KeySort) { o.IP = true })},
+	{0, "semver", noArgument, "", "compare semantic versions", set(func(o *KeySort) { o.SemVer = true })},
+	{0, "semver-allow-v", noArgument, "", "accept a leading v in semantic versions", set(func(o *KeySort) { o.SemVerV = true })},
+	{0, "natural", noArgument, "", "compare numbers inside text by value", set(func(o *KeySort) { o.Natural = true })},
+	{0, "duration", noArgument, "", "compare durations (250ms, 1h30m, 3d, HH:MM:SS)", set(func(o *KeySort) { o.Duration = true })},
+	{0, "csv", noArgument, "", "input is CSV", set(func(o *KeySort) { o.CSV = true })},
+	{0, "tsv", noArgument, "", "input is TSV", set(func(o *KeySort) { o.TSV = true })},
+	{0, "no-header", noArgument, "", "CSV or TSV input has no header row", set(func(o *KeySort) { o.NoHeader = true })},
+	{0, "jsonl", noArgument, "", "input is JSON Lines, keys are JSON paths", set(func(o *KeySort) { o.JSONL = true })},
+	{0, "json", noArgument, "", "input is a JSON array, keys are JSON paths", set(func(o *KeySort) { o.JSON = true })},
+	{0, "json-missing", requiredArgument, "WHERE", "put null and missing JSON values first or last", placement(func(o *KeySort, value string) { o.JSONMissing = value }, InvalidFirst, InvalidLast)},
+	{0, "max-line-length", requiredArgument, "N", "fail on lines longer than N bytes", func(value string, o *KeySort) error {
+		length, err := strconv.Atoi(value)
+		if err != nil || length <= 0 {
+			return invalidNumber(value)
+		}
+		o.MaxLineLength = length
+		return nil
+	}},
+	{0, "header", requiredArgument, "N", "keep the first N lines in place", lineCount(func(o *KeySort, lines int) { o.HeaderLines = lines })},
+	{0, "footer", requiredArgument, "N", "keep the last N lines in place", lineCount(func(o *KeySort, lines int) { o.FooterLines = lines })},
+	{0, "key-regex", requiredArgument, "REGEXP", "take the key from a match of REGEXP", func(value string, o *KeySort) error {
+		pattern, err := regexp.Compile(value)
+		if err != nil || value == "" {
+			return invalidArgument(value)
+		}
+		o.KeyRegex = pattern
+		return nil
+	}},
+	{0, "key-regex-nomatch", requiredArgument, "WHERE", "put lines REGEXP does not match first, last or report an error", placement(func(o *KeySort, value string) { o.KeyRegexNoMatch = value }, InvalidFirst, InvalidLast, InvalidError)},
+	{0, "key-from-end", requiredArgument, "N[:TYPE]", "sort by the Nth column from the end of the line", func(value string, o *KeySort) error {
+		position, _, _ := strings.Cut(value, ":")
+		if number, err := strconv.Atoi(position); err != nil || number <= 0 {
+			return invalidNumber(value)
+		}
+		key, err := parseKey("-" + value)
+		if err != nil {
+			return err
+		}
+		o.Keys = append(o.Keys, key)
+		return nil
+	}},
+	{0, "columns", requiredArgument, "START-END[:TYPE],...", "sort by fixed-width column ranges", func(value string, o *KeySort) error {
+		keys, err := parseColumns(value)
+		if err != nil {
+			return err
+		}
+		o.Keys = append(o.Keys, keys...)
+		return nil
+	}},
+	{0, "columns-unit", requiredArgument, "UNIT", "count --columns positions in byte, rune or width", placement(func(o *KeySort, value string) { o.ColumnsUnit = value }, UnitByte, UnitRune, UnitWidth)},
+	{0, "preserve-line-endings", noArgument, "", "end lines with CRLF when the input does", set(func(o *KeySort) { o.PreserveLineEndings = true })},
+	{0, "strip-bom", noArgument, "", "do not write the UTF-8 byte order mark of the input", set(func(o *KeySort) { o.StripBOM = true })},
+	{0, "preserve-final-newline", noArgument, "", "leave the last line unterminated when the input does", set(func(o *KeySort) { o.PreserveFinalNewline = true })},
+	{0, "debug", noArgument, "", "annotate the part of each line used to sort, and warn about questionable usage to stderr", set(func(o *KeySort) { o.Debug = true })},
+	{0, "lang", requiredArgument, "LANG", "language of messages: en or ru, by default from LC_ALL, LC_MESSAGES or LANG", func(value string, o *KeySort) error {
+		if _, ok := messages.ParseLanguage(value); !ok {
+			return invalidArgument(value)
+		}
+		o.Language = value
+		return nil
+	}},
+	{0, "help", noArgument, "", "display this help and exit", func(string, *KeySort) error { return ErrHelp }},
+	{0, "version", noArgument, "", "output version information and exit", func(string, *KeySort) error { return ErrVersion }},
+}
+
+// set makes the apply function of an option without an argument
+func set(apply func(o *KeySort)) func(string, *KeySort) error {
+	return func(_ string, o *KeySort) error {
+		apply(o)
+		return nil
+	}
+}
+
+// placement makes the apply function of an option taking one of the given words
+func placement(apply func(o *KeySort, value string), words ...string) func(string, *KeySort) error {
+	return func(value string, o *KeySort) error {
+		for _, word := range words {
+			if value == word {
+				apply(o, value)
+				return nil
+			}
+		}
+		return invalidArgument(value)
+	}
+}
+
+// lineCount makes the apply function of an option taking a number of lines
+func lineCount(apply func(o *KeySort, lines int)) func(string, *KeySort) error {
+	return func(value string, o *KeySort) error {
+		lines, err := strconv.Atoi(value)
+		if err != nil || lines < 0 {
+			return invalidNumber(value)
+		}
+		apply(o, lines)
+		return nil
+	}
+}
+
+// addKey adds a -k key; the first key is the column of SortByColumn
+func addKey(value string, o *KeySort) error {
+	key, err := parseKey(value)
+	if err != nil {
+		return err
+	}
+	if !o.SortByColumn {
+		o.SortByColumn = true
+		o.ColumnNumber = key.ColumnNumber
+	}
+	o.Keys = append(o.Keys, key)
+	return nil
+}
+
+func invalidArgument(value string) error {
+	return messages.Errorf("%w '%s'", ErrInvalidArgument, value)
+}
+
+func invalidNumber(value string) error {
+	return messages.Errorf("%w '%s'", ErrInvalidNumber, value)
+}
+
+// optionFailed names the option in the error returned by its apply function
+// ErrHelp and ErrVersion are requests rather than errors and are returned as they are
+func optionFailed(err error, name string) error {
+	if err == ErrHelp || err == ErrVersion {
+		return err
+	}
+	return messages.Errorf("%w for '%s'", err, name)
+}
+
+// shortOption finds the option with a single-letter name
+func shortOption(name rune) (option, bool) {
+	for _, opt := range optionTable {
+		if opt.short == name {
+			return opt, true
+		}
+	}
+	return option{}, false
+}
+
+// longOption finds the option with a long name or an unambiguous prefix of one
+func longOption(name string) (option, error) {
+	var matches []option
+	for _, opt := range optionTable {
+		if opt.long == name {
+			return opt, nil
+		}
+		if strings.HasPrefix(opt.long, name) {
+			matches = append(matches, opt)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return option{}, &optionError{ErrUnknownOption, "unrecognized option '--%s'", []any{name}}
+	case 1:
+		return matches[0], nil
+	}
+
+	possibilities := make([]string, len(matches))
+	for i, opt := range matches {
+		possibilities[i] = "'--" + opt.long + "'"
+	}
+	return option{}, &optionError{ErrUnknownOption, "option '--%s' is ambiguous; possibilities: %s", []any{name, strings.Join(possibilities, " ")}}
+}
+
+// parseLongOption Setting the option in arg, which is --name or --name=value
+// A required value may also be the next argument; returns the number of following arguments used
+func parseLongOption(arg string, next []string, optionSort *KeySort) (int, error) {
+	name, value, hasValue := strings.Cut(arg[2:], "=")
+	opt, err := longOption(name)
+	if err != nil {
+		return 0, err
+	}
+
+	used := 0
+	switch opt.arg {
+	case noArgument:
+		if hasValue {
+			return 0, &optionError{ErrInvalidArgument, "option '--%s' doesn't allow an argument", []any{opt.long}}
+		}
+	case requiredArgument:
+		if !hasValue {
+			if len(next) == 0 {
+				return 0, &optionError{ErrMissingArgument, "option '--%s' requires an argument", []any{opt.long}}
+			}
+			value = next[0]
+			used = 1
+		}
+	}
+
+	if err := opt.apply(value, optionSort); err != nil {
+		return 0, optionFailed(err, "--"+opt.long)
+	}
+	return used, nil
+}
+
+// parseFlag Setting the single-letter options in flags, the text after "-"
+// An option with an argument takes the rest of flags or else the next argument;
+// returns the number of following arguments used
+func parseFlag(flags string, next []string, optionSort *KeySort) (int, error) {
+	for i, name := range flags {
+		opt, ok := shortOption(name)
+		if !ok {
+			return 0, &optionError{ErrUnknownOption, "invalid option -- '%c'", []any{name}}
+		}
+
+		if opt.arg == noArgument {
+			if err := opt.apply("", optionSort); err != nil {
+				return 0, optionFailed(err, "-"+string(name))
+			}
+			continue
+		}
+
+		value := flags[i+len(string(name)):]
+		used := 0
+		if value == "" {
+			if len(next) == 0 {
+				return 0, &optionError{ErrMissingArgument, "option requires an argument -- '%c'", []any{name}}
+			}
+			value = next[0]
+			used = 1
+		}
+
+		if err := opt.apply(value, optionSort); err != nil {
+			return 0, optionFailed(err, "-"+string(name))
+		}
+		return used, nil
+	}
+	return 0, nil
+}
@@ -1,21 +1,50 @@
 package args
 
 import (
-	"errors"
-	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzmsq/sort_utility/internal/messages"
 )
 
 // Error variables for argument parsing
 var (
 	// ErrMissingArgument is returned when an option requires an argument but none is provided
-	ErrMissingArgument = errors.New("option requires an argument")
+	ErrMissingArgument = messages.New("option requires an argument")
 	// ErrInvalidNumber is returned when a provided argument is not a valid number
-	ErrInvalidNumber = errors.New("invalid number")
+	ErrInvalidNumber = messages.New("invalid number")
 	// ErrUnknownOption is returned when an unknown option is encountered
-	ErrUnknownOption = errors.New("unknown option")
+	ErrUnknownOption = messages.New("unknown option")
 	// ErrFileNotFound is returned when the specified file does not exist
-	ErrFileNotFound = errors.New("no such file or directory")
+	ErrFileNotFound = messages.New("no such file or directory")
+	// ErrInvalidArgument is returned when an option argument is not one of the accepted values
+	ErrInvalidArgument = messages.New("invalid argument")
+	// errZeroColumn is returned when a field key or the sort column is 0, which is neither a column nor one from the end
+	errZeroColumn = messages.Errorf("%w: field number is zero", ErrInvalidArgument)
+	// ErrUnknownKeyType is returned when a -k value names an unknown key type
+	ErrUnknownKeyType = messages.New("unknown key type")
+	// ErrUnknownColumn is returned when a -k value names a column missing from the CSV header
+	ErrUnknownColumn = messages.New("unknown column")
+	// ErrHelp is returned by ParseArgs when --help is given, see WriteHelp
+	ErrHelp = messages.New("help requested")
+	// ErrVersion is returned by ParseArgs when --version is given
+	ErrVersion = messages.New("version requested")
+)
+
+// Placement of values that cannot be parsed or are missing
+const (
+	InvalidLast  = "last"  // Unparsable values go after all valid ones
+	InvalidFirst = "first" // Unparsable values go before all valid ones
+	InvalidError = "error" // Unparsable values abort the sort
+)
+
+// Units of --columns positions
+const (
+	UnitByte  = "byte"  // Positions count bytes
+	UnitRune  = "rune"  // Positions count characters
+	UnitWidth = "width" // Positions count terminal cells, East Asian wide characters take two
 )
 
 // KeySort Sort key
@@ -29,55 +58,139 @@ type KeySort struct {
 	SkipBlanks   bool // Skip leading blanks when finding end
 	IsSorted     bool // Check if the data is sorted
 	HumanNumeric bool // Flag for sorting by human-readable
+	Date         bool // Flag for sorting by date and time
+	IP           bool // Flag for sorting by IP address or CIDR prefix
+	SemVer       bool // Flag for sorting by semantic version precedence
+	Natural      bool // Flag for natural sorting of embedded numbers
+	Duration     bool // Flag for sorting by duration
+
+	DateLayout     string         // Go time layout for dates, empty means auto-detect
+	DateZone       *time.Location // Time zone for dates without an explicit offset, nil means local
+	DateInvalid    string         // Placement of unparsable dates: first, last or error
+	SemVerV        bool           // Accept a leading "v" in semantic versions
+	CSV            bool           // Input is comma-separated values
+	TSV            bool           // Input is tab-separated values
+	NoHeader       bool           // CSV or TSV input has no header row
+	JSONL          bool           // Input is JSON Lines, keys are JSON paths
+	JSON           bool           // Input is a JSON array whose elements are sorted, keys are JSON paths
+	JSONMissing    string         // Placement of null and missing JSON values: first or last
+	ZeroTerminated bool           // Records end with NUL instead of newline, for input and output
+	MaxLineLength  int            // Longest accepted line in bytes, 0 means no limit
+	HeaderLines    int            // Number of leading lines kept in place
+	FooterLines    int            // Number of trailing lines kept in place
+
+	KeyRegex        *regexp.Regexp // Takes the key from the group named "key", the first group or the whole match
+	KeyRegexNoMatch string         // Placement of lines the regexp does not match: first, last or error
+	ColumnsUnit     string         // Unit of --columns positions: byte, rune or width
+	Type            string         // Registered key type set with --sort, see RegisterKeyType
+	TypeArg         string         // Argument of the registered key type
+
+	PreserveLineEndings  bool // Write "\r\n" after each line when the input uses CRLF line endings
+	StripBOM             bool // Drop the UTF-8 byte order mark of the input instead of writing it back
+	PreserveFinalNewline bool // Leave the last line unterminated when the input has no final newline
+
+	Language string // Language of messages, en or ru; the command takes it from --lang or the locale, empty means English
+	Debug    bool   // Underline the keys of every output line and warn about questionable options, see --debug
+
+	Keys []Key // Sort keys given with -k, in priority order
+}
+
+// Key A single sort key given with -k N[:TYPE[=ARG]]
+// A key without a type uses the global sort type
+// In CSV and TSV mode N may also be a column name from the header row,
+// in JSON Lines and JSON mode it is a path such as .user.name
+type Key struct {
+	ColumnNumber int    // Num of the column to compare, negative counts from the end
+	ColumnName   string // Header name of the column to compare, resolved to ColumnNumber when reading
+	Path         string // JSON path of the value to compare, resolved to ColumnNumber when reading
+	Start        int    // First position of a --columns range, 1-based
+	End          int    // Last position of a --columns range, 0 means the end of the line
+	Numeric      bool   // Compare the column as a number
+	Month        bool   // Compare the column as a month name
+	HumanNumeric bool   // Compare the column as a human-readable size
+	Date         bool   // Compare the column as a date
+	DateLayout   string // Go time layout for the date, empty means auto-detect
+	IP           bool   // Compare the column as an IP address or CIDR prefix
+	SemVer       bool   // Compare the column as a semantic version
+	SemVerV      bool   // Accept a leading "v" in the semantic version
+	Natural      bool   // Compare the column in natural order
+	Duration     bool   // Compare the column as a duration
+	Type         string // Compare the column with a registered key type, see RegisterKeyType
+	TypeArg      string // Argument of the registered key type
+	EndColumn    int    // Last column of a -k F1,F2 key, 0 means the key is the single column
+	Reverse      bool   // Sort this key in descending order
+}
+
+// HasType reports whether the key selects its own sort type
+func (k Key) HasType() bool {
+	return k.Numeric || k.Month || k.HumanNumeric || k.Date || k.IP || k.SemVer || k.Natural || k.Duration || k.Type != ""
+}
+
+// MessageLanguage returns the language of messages the options select, English when none is set
+func (options *KeySort) MessageLanguage() messages.Language {
+	if language, ok := messages.ParseLanguage(options.Language); ok {
+		return language
+	}
+	return messages.English
 }
 
-// ParseArgs Parsing flags and file name
+// ParseArgs Parsing flags and file name the way GNU getopt_long does
+// Options and the file may come in any order, "--" ends the options and "-" is stdin
+// With an error the options parsed before it are returned, so --lang applies to the diagnostic
 func ParseArgs(args []string) (string, *KeySort, error) {
-	var filePath string
+	var files []string
 	options := &KeySort{}
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 
-		if arg[0] == '-' {
-			// A separate case for parsing the k flag
-			if arg == "-k" {
-				if i+1 >= len(args) {
-					return "", nil, fmt.Errorf("%w -- k", ErrMissingArgument)
-				}
-
-				columnNum, err := strconv.Atoi(args[i+1])
-				if err != nil || columnNum <= 0 {
-					return "", nil, fmt.Errorf("%w: %s", ErrInvalidNumber, args[i+1])
-				}
-				options.SortByColumn = true
-				options.ColumnNumber = columnNum
-				i++
-			} else {
-				err := parseFlag(arg[1:], options)
-				if err != nil {
-					return "", nil, err
-				}
-			}
-		} else if filePath == "" {
-			filePath = arg
-		} else {
-			return "", nil, fmt.Errorf("cannot read: %s: %w", filePath, ErrFileNotFound)
+		var used int
+		var err error
+		switch {
+		case arg == "--":
+			files = append(files, args[i+1:]...)
+			i = len(args)
+		case strings.HasPrefix(arg, "--"):
+			used, err = parseLongOption(arg, args[i+1:], options)
+		case len(arg) > 1 && arg[0] == '-':
+			used, err = parseFlag(arg[1:], args[i+1:], options)
+		default:
+			files = append(files, arg)
+		}
+		if err != nil {
+			return "", options, err
 		}
+		i += used
 	}
 
-	if !options.SortByColumn {
-		options.SortByColumn = true
-		options.ColumnNumber = 1
+	var filePath string
+	if len(files) > 1 {
+		return "", options, &optionError{ErrInvalidArgument, "extra operand '%s'", []any{files[1]}}
+	}
+	if len(files) == 1 {
+		filePath = files[0]
+		if filePath == "" {
+			return "", options, messages.Errorf("cannot read: '': %w", ErrFileNotFound)
+		}
 	}
 
-	if err := validateFlags(options); err != nil {
-		return "", nil, err
+	if err := options.Validate(); err != nil {
+		return "", options, err
 	}
 
 	return filePath, options, nil
 }
 
+// Validate sorts by column 1 when no column is set and checks that the options can be used together
+// Columns count from 1, negative ones from the end, so a field key or column of 0 is an error
+func (options *KeySort) Validate() error {
+	if !options.SortByColumn {
+		options.SortByColumn = true
+		options.ColumnNumber = 1
+	}
+	return validateFlags(options)
+}
+
 func validateFlags(options *KeySort) error {
 	sortFlags := 0
 	if options.Numeric {
@@ -89,41 +202,268 @@ func validateFlags(options *KeySort) error {
 	if options.HumanNumeric {
 		sortFlags++
 	}
+	if options.Date {
+		sortFlags++
+	}
+	if options.IP {
+		sortFlags++
+	}
+	if options.SemVer {
+		sortFlags++
+	}
+	if options.Natural {
+		sortFlags++
+	}
+	if options.Duration {
+		sortFlags++
+	}
+	if options.Type != "" {
+		sortFlags++
+	}
 
 	if sortFlags > 1 {
-		return errors.New("conflicting sort options: only one of -n, -M, -h can be used")
+		return messages.New("conflicting sort options: only one of -n, -M, -h, --date, --ip, --semver, --natural, --duration, --sort can be used")
+	}
+
+	formats := 0
+	for _, format := range []bool{options.CSV, options.TSV, options.JSONL, options.JSON} {
+		if format {
+			formats++
+		}
+	}
+	if formats > 1 {
+		return messages.New("conflicting input formats: only one of --csv, --tsv, --jsonl, --json can be used")
+	}
+
+	if options.KeyRegex != nil && (formats > 0 || len(options.Keys) > 0) {
+		return messages.New("--key-regex cannot be used with -k, --csv, --tsv, --jsonl or --json")
+	}
+
+	fixed := 0
+	for _, key := range options.Keys {
+		if key.Start > 0 {
+			fixed++
+		}
+	}
+	if fixed > 0 && (fixed < len(options.Keys) || formats > 0 || options.KeyRegex != nil) {
+		return messages.New("--columns cannot be used with field keys, --key-regex, --csv, --tsv, --jsonl or --json")
+	}
+
+	if options.ZeroTerminated && (options.CSV || options.TSV || options.JSON) {
+		return messages.New("-z cannot be used with --csv, --tsv or --json")
+	}
+
+	if options.JSONMissing != "" && !options.JSONL && !options.JSON {
+		return messages.New("--json-missing requires --jsonl or --json")
+	}
+
+	if options.JSON && (options.HeaderLines > 0 || options.FooterLines > 0) {
+		return messages.New("--header and --footer cannot be used with --json")
+	}
+
+	for _, key := range options.Keys {
+		if key.EndColumn > 0 && (formats > 0 || options.KeyRegex != nil || fixed > 0) {
+			return messages.Errorf("column ranges in -k cannot be used with --csv, --tsv, --jsonl, --json, --key-regex or --columns: %d,%d", key.ColumnNumber, key.EndColumn)
+		}
+		if key.ColumnName != "" && !options.CSV && !options.TSV {
+			return messages.Errorf("column names in -k require --csv or --tsv: %s", key.ColumnName)
+		}
+		if key.Path != "" && !options.JSONL && !options.JSON {
+			return messages.Errorf("JSON paths in -k require --jsonl or --json: %s", key.Path)
+		}
+		if key.Path == "" && (options.JSONL || options.JSON) {
+			return messages.Errorf("keys in --jsonl and --json mode must be JSON paths: %d", key.ColumnNumber)
+		}
+		if key.ColumnNumber == 0 && key.ColumnName == "" && key.Path == "" && key.Start == 0 {
+			return errZeroColumn
+		}
+	}
+	if len(options.Keys) == 0 && options.ColumnNumber == 0 {
+		return errZeroColumn
 	}
 
 	if options.IsSorted && (options.Reverse || options.Unique) {
-		return errors.New("check mode (-c) cannot be used with -r or -u")
+		return messages.New("check mode (-c) cannot be used with -r or -u")
+	}
+
+	if options.Type != "" {
+		if err := validateKeyType(options.Type, options.TypeArg, options); err != nil {
+			return err
+		}
+	}
+	for _, key := range options.Keys {
+		if key.Type != "" {
+			if err := validateKeyType(key.Type, key.TypeArg, options); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-// parseFlag A simple function for setting flags in a KeySort structure
-func parseFlag(keys string, optionSort *KeySort) error {
-	for _, key := range keys {
-		switch key {
-		case 'k':
-			return fmt.Errorf("-k %w", ErrMissingArgument)
-		case 'n':
-			optionSort.Numeric = true
-		case 'r':
-			optionSort.Reverse = true
-		case 'u':
-			optionSort.Unique = true
-		case 'M':
-			optionSort.Month = true
+// parseKey Parsing a -k value of the form N[:TYPE[=ARG]], where N is a column number or name
+// A negative N counts columns from the end of the line: -1 is the last one
+// GNU keys F1[OPTS],F2[OPTS] and FOPTS are accepted as well, see parseKeyDef
+func parseKey(spec string) (Key, error) {
+	if match := keyDef.FindStringSubmatch(spec); match != nil && (match[4] != "" || match[3] != "") {
+		return parseKeyDef(spec, match)
+	}
+
+	column, keyType, hasType := strings.Cut(spec, ":")
+
+	var key Key
+	if strings.HasPrefix(column, ".") {
+		key.Path = column
+	} else if column != "" && (column[0] < '0' || column[0] > '9') && column[0] != '-' && column[0] != '+' {
+		key.ColumnName = column
+	} else {
+		columnNum, err := strconv.Atoi(column)
+		if err != nil || columnNum == 0 {
+			return Key{}, messages.Errorf("%w: %s", ErrInvalidNumber, spec)
+		}
+		key.ColumnNumber = columnNum
+	}
+
+	if !hasType {
+		return key, nil
+	}
+
+	if err := parseKeyType(&key, keyType); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+// keyDef matches a GNU key F[.C][OPTS][,F[.C][OPTS]]
+var keyDef = regexp.MustCompile(`^(\d+)(\.\d+)?([a-zA-Z]*)(?:,(\d+)(\.\d+)?([a-zA-Z]*))?$`)
+
+// parseKeyDef Parsing a GNU key: columns F1 through F2 with the ordering letters b, h, M, n and r
+// Without F2 the key is the single column F1. Character positions are not supported
+// except for the first character of F1 and the end of F2 (.0)
+func parseKeyDef(spec string, match []string) (Key, error) {
+	var key Key
+
+	start, err := strconv.Atoi(match[1])
+	if err != nil || start == 0 {
+		return Key{}, messages.Errorf("%w: %s", ErrInvalidNumber, spec)
+	}
+	key.ColumnNumber = start
+
+	if match[4] != "" {
+		end, err := strconv.Atoi(match[4])
+		if err != nil || end < start {
+			return Key{}, messages.Errorf("%w: %s", ErrInvalidNumber, spec)
+		}
+		if end > start {
+			key.EndColumn = end
+		}
+	}
+
+	if (match[2] != "" && match[2] != ".1") || (match[5] != "" && match[5] != ".0") {
+		return Key{}, messages.Errorf("%w: character positions are not supported: %s", ErrInvalidArgument, spec)
+	}
+
+	var keyType rune
+	for _, letter := range match[3] + match[6] {
+		switch letter {
 		case 'b':
-			optionSort.SkipBlanks = true
-		case 'c':
-			optionSort.IsSorted = true
-		case 'h':
-			optionSort.HumanNumeric = true
+			// Fields are split on blanks, so they never start with one
+		case 'r':
+			key.Reverse = true
+		case 'n', 'M', 'h':
+			if keyType != 0 && keyType != letter {
+				return Key{}, messages.Errorf("%w: conflicting key types: %s", ErrInvalidArgument, spec)
+			}
+			keyType = letter
+			if err := parseKeyType(&key, string(letter)); err != nil {
+				return Key{}, err
+			}
 		default:
-			return fmt.Errorf("%w: %c", ErrUnknownOption, key)
+			return Key{}, messages.Errorf("%w: key option '%c': %s", ErrUnknownKeyType, letter, spec)
 		}
 	}
+	return key, nil
+}
+
+// parseKeyType Setting the key type from TYPE[=ARG]
+func parseKeyType(key *Key, keyType string) error {
+	name, arg, _ := strings.Cut(keyType, "=")
+	switch name {
+	case "n", "numeric":
+		key.Numeric = true
+	case "M", "month":
+		key.Month = true
+	case "h", "human":
+		key.HumanNumeric = true
+	case "date":
+		key.Date = true
+		key.DateLayout = arg
+	case "ip":
+		key.IP = true
+	case "semver":
+		if arg != "" && arg != "v" {
+			return messages.Errorf("%w: %s", ErrInvalidArgument, keyType)
+		}
+		key.SemVer = true
+		key.SemVerV = arg == "v"
+	case "natural":
+		key.Natural = true
+	case "duration":
+		key.Duration = true
+	default:
+		if _, ok := LookupKeyType(name); !ok {
+			return messages.Errorf("%w: %s", ErrUnknownKeyType, name)
+		}
+		key.Type = name
+		key.TypeArg = arg
+	}
 	return nil
 }
+
+// setSortType Setting the global sort type to the type of key
+func setSortType(optionSort *KeySort, key Key) {
+	optionSort.Numeric = key.Numeric
+	optionSort.Month = key.Month
+	optionSort.HumanNumeric = key.HumanNumeric
+	optionSort.Date = key.Date
+	optionSort.DateLayout = key.DateLayout
+	optionSort.IP = key.IP
+	optionSort.SemVer = key.SemVer
+	optionSort.SemVerV = optionSort.SemVerV || key.SemVerV
+	optionSort.Natural = key.Natural
+	optionSort.Duration = key.Duration
+	optionSort.Type = key.Type
+	optionSort.TypeArg = key.TypeArg
+}
+
+// parseColumns Parsing a --columns value: comma-separated START-END[:TYPE[=ARG]] ranges
+// END may be omitted to take the rest of the line
+func parseColumns(spec string) ([]Key, error) {
+	var keys []Key
+	for _, columns := range strings.Split(spec, ",") {
+		positions, keyType, hasType := strings.Cut(columns, ":")
+
+		first, last, _ := strings.Cut(positions, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start <= 0 {
+			return nil, messages.Errorf("%w: --columns=%s", ErrInvalidNumber, columns)
+		}
+		end := 0
+		if last != "" {
+			end, err = strconv.Atoi(last)
+			if err != nil || end < start {
+				return nil, messages.Errorf("%w: --columns=%s", ErrInvalidNumber, columns)
+			}
+		}
+
+		key := Key{Start: start, End: end}
+		if hasType {
+			if err := parseKeyType(&key, keyType); err != nil {
+				return nil, err
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -1,6 +1,8 @@
 package args
 
 import (
+	"reflect"
+	"regexp"
 	"testing"
 )
 
@@ -34,7 +36,189 @@ func TestParseArgs(t *testing.T) {
 			name:       "column sort",
 			args:       []string{"-k", "3", "test.txt"},
 			expectFile: "test.txt",
-			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 3},
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 3, Keys: []Key{{ColumnNumber: 3}}},
+		},
+		{
+			name:       "date sort",
+			args:       []string{"--date", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Date: true},
+		},
+		{
+			name:       "date key with layout",
+			args:       []string{"-k", "2:date=02.01.2006", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 2, Keys: []Key{{ColumnNumber: 2, Date: true, DateLayout: "02.01.2006"}}},
+		},
+		{
+			name:       "ip sort",
+			args:       []string{"--ip", "-k", "2", "hosts.txt"},
+			expectFile: "hosts.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 2, IP: true, Keys: []Key{{ColumnNumber: 2}}},
+		},
+		{
+			name:        "conflicting natural and semver",
+			args:        []string{"--natural", "--semver", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:       "csv column name",
+			args:       []string{"--csv", "-k", "age:n", "people.csv"},
+			expectFile: "people.csv",
+			expectOpts: &KeySort{SortByColumn: true, CSV: true, Keys: []Key{{ColumnName: "age", Numeric: true}}},
+		},
+		{
+			name:        "column name without csv",
+			args:        []string{"-k", "age", "people.csv"},
+			expectError: true,
+		},
+		{
+			name:        "json missing without json",
+			args:        []string{"--json-missing=first", "-k", "2", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "csv and tsv",
+			args:        []string{"--csv", "--tsv", "people.csv"},
+			expectError: true,
+		},
+		{
+			name:       "jsonl path key",
+			args:       []string{"--jsonl", "-k", ".request.latency_ms:n", "log.ndjson"},
+			expectFile: "log.ndjson",
+			expectOpts: &KeySort{SortByColumn: true, JSONL: true, Keys: []Key{{Path: ".request.latency_ms", Numeric: true}}},
+		},
+		{
+			name:        "jsonl with column number",
+			args:        []string{"--jsonl", "-k", "2", "log.ndjson"},
+			expectError: true,
+		},
+		{
+			name:       "json array path key",
+			args:       []string{"--json", "-k", ".prio:n", "tasks.json"},
+			expectFile: "tasks.json",
+			expectOpts: &KeySort{SortByColumn: true, JSON: true, Keys: []Key{{Path: ".prio", Numeric: true}}},
+		},
+		{
+			name:        "json and jsonl",
+			args:        []string{"--json", "--jsonl", "tasks.json"},
+			expectError: true,
+		},
+		{
+			name:        "path without jsonl",
+			args:        []string{"-k", ".user.name", "log.ndjson"},
+			expectError: true,
+		},
+		{
+			name:        "invalid missing placement",
+			args:        []string{"--jsonl", "--json-missing=error", "log.ndjson"},
+			expectError: true,
+		},
+		{
+			name:       "header and footer",
+			args:       []string{"--header=1", "--footer=2", "-n", "df.txt"},
+			expectFile: "df.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true, HeaderLines: 1, FooterLines: 2},
+		},
+		{
+			name:        "negative header",
+			args:        []string{"--header=-1", "df.txt"},
+			expectError: true,
+		},
+		{
+			name:        "footer with json",
+			args:        []string{"--json", "--footer=1", "tasks.json"},
+			expectError: true,
+		},
+		{
+			name:       "key regex",
+			args:       []string{"--key-regex=took (\\d+)ms", "--key-regex-nomatch=first", "-n", "app.log"},
+			expectFile: "app.log",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true, KeyRegex: regexp.MustCompile(`took (\d+)ms`), KeyRegexNoMatch: "first"},
+		},
+		{
+			name:        "invalid key regex",
+			args:        []string{"--key-regex=(", "app.log"},
+			expectError: true,
+		},
+		{
+			name:        "key regex with column",
+			args:        []string{"--key-regex=x", "-k", "2", "app.log"},
+			expectError: true,
+		},
+		{
+			name:       "fixed-width columns",
+			args:       []string{"--columns=14-17:n,1-12", "--columns-unit=width", "report.txt"},
+			expectFile: "report.txt",
+			expectOpts: &KeySort{
+				SortByColumn: true,
+				ColumnNumber: 1,
+				ColumnsUnit:  "width",
+				Keys:         []Key{{Start: 14, End: 17, Numeric: true}, {Start: 1, End: 12}},
+			},
+		},
+		{
+			name:        "columns with field key",
+			args:        []string{"--columns=1-5", "-k", "2", "report.txt"},
+			expectError: true,
+		},
+		{
+			name:        "unknown columns unit",
+			args:        []string{"--columns=1-5", "--columns-unit=cell", "report.txt"},
+			expectError: true,
+		},
+		{
+			name:        "zero terminated csv",
+			args:        []string{"-z", "--csv", "data.csv"},
+			expectError: true,
+		},
+		{
+			name:       "line ending options",
+			args:       []string{"--preserve-line-endings", "--strip-bom", "--preserve-final-newline", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, PreserveLineEndings: true, StripBOM: true, PreserveFinalNewline: true},
+		},
+		{
+			name:        "zero max line length",
+			args:        []string{"--max-line-length=0", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "unknown key type",
+			args:        []string{"-k", "2:color", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "invalid date placement",
+			args:        []string{"--date", "--date-invalid=middle", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "unknown time zone",
+			args:        []string{"--date", "--date-tz=Nowhere/City", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "conflicting date and numeric",
+			args:        []string{"--date", "-n", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:       "column from the end",
+			args:       []string{"-k", "-1", "-n", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: -1, Numeric: true, Keys: []Key{{ColumnNumber: -1}}},
+		},
+		{
+			name:       "key from end option",
+			args:       []string{"--key-from-end=2:h", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Keys: []Key{{ColumnNumber: -2, HumanNumeric: true}}},
+		},
+		{
+			name:        "key from end zero",
+			args:        []string{"--key-from-end=0", "test.txt"},
+			expectError: true,
 		},
 		{
 			name:        "missing k argument",
@@ -61,6 +245,78 @@ func TestParseArgs(t *testing.T) {
 			args:        []string{"-c", "-r", "test.txt"},
 			expectError: true,
 		},
+		{
+			name:       "GNU long options",
+			args:       []string{"--numeric-sort", "--reverse", "--key=2,3n", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true, Reverse: true, Keys: []Key{{ColumnNumber: 2, EndColumn: 3, Numeric: true}}},
+		},
+		{
+			name:       "attached key value",
+			args:       []string{"-nk2", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true, Keys: []Key{{ColumnNumber: 2}}},
+		},
+		{
+			name:       "long option value in next argument",
+			args:       []string{"--key", "3", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 3, Keys: []Key{{ColumnNumber: 3}}},
+		},
+		{
+			name:       "abbreviated long option",
+			args:       []string{"--numeric", "test.txt"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true},
+		},
+		{
+			name:       "options after the file",
+			args:       []string{"test.txt", "-n"},
+			expectFile: "test.txt",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true},
+		},
+		{
+			name:       "file named like an option after --",
+			args:       []string{"-n", "--", "-foo"},
+			expectFile: "-foo",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true},
+		},
+		{
+			name:       "stdin",
+			args:       []string{"-"},
+			expectFile: "-",
+			expectOpts: &KeySort{SortByColumn: true, ColumnNumber: 1},
+		},
+		{
+			name:        "file named like an option",
+			args:        []string{"-foo"},
+			expectError: true,
+		},
+		{
+			name:        "empty file name",
+			args:        []string{""},
+			expectError: true,
+		},
+		{
+			name:        "ambiguous long option",
+			args:        []string{"--js", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "argument to option without one",
+			args:        []string{"--reverse=yes", "test.txt"},
+			expectError: true,
+		},
+		{
+			name:        "help",
+			args:        []string{"-n", "--help", "-x"},
+			expectError: true,
+		},
+		{
+			name:        "extra operand",
+			args:        []string{"a.txt", "b.txt"},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,21 +339,27 @@ func TestParseArgs(t *testing.T) {
 				t.Errorf("expected file %q, got %q", tt.expectFile, file)
 			}
 
-			if opts.SortByColumn != tt.expectOpts.SortByColumn {
-				t.Errorf("expected SortByColumn %v, got %v", tt.expectOpts.SortByColumn, opts.SortByColumn)
-			}
-
-			if opts.ColumnNumber != tt.expectOpts.ColumnNumber {
-				t.Errorf("expected ColumnNumber %d, got %d", tt.expectOpts.ColumnNumber, opts.ColumnNumber)
-			}
-
-			if opts.Numeric != tt.expectOpts.Numeric {
-				t.Errorf("expected Numeric %v, got %v", tt.expectOpts.Numeric, opts.Numeric)
+			if !equalOptions(opts, tt.expectOpts) {
+				t.Errorf("expected options %+v, got %+v", *tt.expectOpts, *opts)
 			}
 		})
 	}
 }
 
+// equalOptions compares all options; the regexp and the time zone are compared by pattern and name
+func equalOptions(a, b *KeySort) bool {
+	x, y := *a, *b
+	if (x.KeyRegex == nil) != (y.KeyRegex == nil) || (x.KeyRegex != nil && x.KeyRegex.String() != y.KeyRegex.String()) {
+		return false
+	}
+	if x.DateZone.String() != y.DateZone.String() {
+		return false
+	}
+	x.KeyRegex, y.KeyRegex = nil, nil
+	x.DateZone, y.DateZone = nil, nil
+	return reflect.DeepEqual(x, y)
+}
+
 func TestParseFlag(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -125,6 +387,11 @@ func TestParseFlag(t *testing.T) {
 			flags:     "M",
 			checkFunc: func(ks *KeySort) bool { return ks.Month },
 		},
+		{
+			name:      "zero terminated flag",
+			flags:     "z",
+			checkFunc: func(ks *KeySort) bool { return ks.ZeroTerminated },
+		},
 		{
 			name:      "combined flags",
 			flags:     "nr",
@@ -140,7 +407,7 @@ func TestParseFlag(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			opts := &KeySort{}
-			err := parseFlag(tt.flags, opts)
+			_, err := parseFlag(tt.flags, nil, opts)
 
 			if tt.expectError {
 				if err == nil {
@@ -160,3 +427,133 @@ func TestParseFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    Key
+		expectError bool
+	}{
+		{"column only", "3", Key{ColumnNumber: 3}, false},
+		{"numeric key", "2:n", Key{ColumnNumber: 2, Numeric: true}, false},
+		{"month key", "1:month", Key{ColumnNumber: 1, Month: true}, false},
+		{"date key", "4:date", Key{ColumnNumber: 4, Date: true}, false},
+		{"date key with layout", "4:date=Jan _2 15:04:05", Key{ColumnNumber: 4, Date: true, DateLayout: "Jan _2 15:04:05"}, false},
+		{"ip key", "2:ip", Key{ColumnNumber: 2, IP: true}, false},
+		{"semver key", "1:semver", Key{ColumnNumber: 1, SemVer: true}, false},
+		{"semver key with v", "1:semver=v", Key{ColumnNumber: 1, SemVer: true, SemVerV: true}, false},
+		{"semver key with bad argument", "1:semver=x", Key{}, true},
+		{"natural key", "2:natural", Key{ColumnNumber: 2, Natural: true}, false},
+		{"duration key", "3:duration", Key{ColumnNumber: 3, Duration: true}, false},
+		{"column name", "age:n", Key{ColumnName: "age", Numeric: true}, false},
+		{"json path", ".user.name:natural", Key{Path: ".user.name", Natural: true}, false},
+		{"last column", "-1", Key{ColumnNumber: -1}, false},
+		{"column from end with type", "-2:n", Key{ColumnNumber: -2, Numeric: true}, false},
+		{"zero column", "0:date", Key{}, true},
+		{"unknown type", "1:color", Key{}, true},
+		{"GNU column range", "2,3n", Key{ColumnNumber: 2, EndColumn: 3, Numeric: true}, false},
+		{"GNU single column", "2,2", Key{ColumnNumber: 2}, false},
+		{"GNU options on both ends", "1h,2r", Key{ColumnNumber: 1, EndColumn: 2, HumanNumeric: true, Reverse: true}, false},
+		{"GNU options without end", "2M", Key{ColumnNumber: 2, Month: true}, false},
+		{"GNU end of field", "1.1,2.0", Key{ColumnNumber: 1, EndColumn: 2}, false},
+		{"GNU character position", "2.3", Key{}, true},
+		{"GNU end before start", "3,2", Key{}, true},
+		{"GNU conflicting types", "2nM", Key{}, true},
+		{"GNU unsupported option", "2f", Key{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parseKey(tt.spec)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if key != tt.expected {
+				t.Errorf("parseKey(%q) = %+v, want %+v", tt.spec, key, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    []Key
+		expectError bool
+	}{
+		{"single range", "3-7", []Key{{Start: 3, End: 7}}, false},
+		{"open range", "10-", []Key{{Start: 10}}, false},
+		{"typed ranges", "1-4:n,6-9:date=0201", []Key{{Start: 1, End: 4, Numeric: true}, {Start: 6, End: 9, Date: true, DateLayout: "0201"}}, false},
+		{"end before start", "5-3", nil, true},
+		{"zero start", "0-3", nil, true},
+		{"not a number", "a-b", nil, true},
+		{"unknown type", "1-2:color", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseColumns(tt.spec)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if len(keys) != len(tt.expected) {
+				t.Fatalf("parseColumns(%q) returned %d keys, want %d", tt.spec, len(keys), len(tt.expected))
+			}
+			for i, key := range keys {
+				if key != tt.expected[i] {
+					t.Errorf("parseColumns(%q)[%d] = %+v, want %+v", tt.spec, i, key, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseArgsDiagnostics(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-x"}, "invalid option -- 'x'"},
+		{[]string{"--colour"}, "unrecognized option '--colour'"},
+		{[]string{"-k"}, "option requires an argument -- 'k'"},
+		{[]string{"--key"}, "option '--key' requires an argument"},
+		{[]string{"--reverse=yes"}, "option '--reverse' doesn't allow an argument"},
+		{[]string{"--jso"}, "option '--jso' is ambiguous; possibilities: '--jsonl' '--json' '--json-missing'"},
+		{[]string{"a.txt", "b.txt"}, "extra operand 'b.txt'"},
+		{[]string{"--header=x"}, "invalid number 'x' for '--header'"},
+		{[]string{"--json-missing", "middle"}, "invalid argument 'middle' for '--json-missing'"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := ParseArgs(tt.args)
+		if err == nil {
+			t.Errorf("ParseArgs(%q) expected error %q, got none", tt.args, tt.expected)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("ParseArgs(%q) error = %q, expected %q", tt.args, err.Error(), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package file
+
+import (
+	"strings"
+	"unicode"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+)
+
+// wideRanges are the East Asian Wide and Fullwidth blocks that take two terminal cells
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo initial consonants
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi radicals, CJK symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi syllables and radicals
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F300, 0x1F64F}, // Pictographs and emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x20000, 0x2FFFD}, // CJK unified ideographs extensions B and later
+	{0x30000, 0x3FFFD}, // CJK unified ideographs extension G
+}
+
+// runeWidth returns the number of terminal cells r takes
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide[0] && r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// cutColumns returns the part of line between the 1-based positions start and end inclusive
+// End 0 means the end of the line. The second result is false when the line ends before start
+func cutColumns(line string, start, end int, unit string) (string, bool) {
+	from, to, ok := columnSpan(line, start, end, unit)
+	return line[from:to], ok
+}
+
+// columnSpan returns the byte offsets in line of the part cutColumns returns
+// In width units a wide character belongs to the range its first cell falls in, and
+// zero-width characters stay with the character before them
+func columnSpan(line string, start, end int, unit string) (int, int, bool) {
+	if unit == p.UnitByte {
+		if start > len(line) {
+			return 0, 0, false
+		}
+		if end == 0 || end > len(line) {
+			end = len(line)
+		}
+		return start - 1, end, true
+	}
+
+	from, to := -1, len(line)
+	position := 1
+	for i, r := range line {
+		width := 1
+		if unit == p.UnitWidth {
+			width = runeWidth(r)
+			if width == 0 && i > 0 {
+				continue
+			}
+		}
+
+		if end != 0 && position > end {
+			to = i
+			break
+		}
+		if from < 0 && position >= start {
+			from = i
+		}
+		position += width
+	}
+
+	if from < 0 {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// fixedColumns reports whether the keys are --columns ranges
+func fixedColumns(options *p.KeySort) bool {
+	return len(options.Keys) > 0 && options.Keys[0].Start > 0
+}
+
+// columnsUnit returns the unit of --columns positions, characters by default
+func columnsUnit(options *p.KeySort) string {
+	if options.ColumnsUnit == "" {
+		return p.UnitRune
+	}
+	return options.ColumnsUnit
+}
+
+// columnRecords makes typed records with one field per --columns range, trimmed of padding
+// Lines that end before a range get a missing value for it
+func columnRecords(lines []string, options *p.KeySort) ([]record, *p.KeySort) {
+	unit := columnsUnit(options)
+
+	resolved := *options
+	resolved.Keys = make([]p.Key, len(options.Keys))
+	for i, key := range options.Keys {
+		key.ColumnNumber = i + 1
+		resolved.Keys[i] = key
+	}
+
+	records := make([]record, len(lines))
+	for n, line := range lines {
+		rec := record{line: line, fields: make([]string, len(options.Keys)), kinds: make([]valueKind, len(options.Keys))}
+		for i, key := range options.Keys {
+			value, ok := cutColumns(line, key.Start, key.End, unit)
+			if ok {
+				rec.fields[i], rec.kinds[i] = strings.TrimSpace(value), kindString
+			}
+		}
+		records[n] = rec
+	}
+	return records, &resolved
+}
@@ -0,0 +1,113 @@
+package file
+
+import (
+	"github.com/rzmsq/sort_utility/internal/args"
+	"testing"
+)
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        rune
+		expected int
+	}{
+		{"latin", 'a', 1},
+		{"cyrillic", 'ж', 1},
+		{"cjk ideograph", '漢', 2},
+		{"hangul", '한', 2},
+		{"fullwidth letter", 'Ａ', 2},
+		{"combining accent", '́', 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runeWidth(tt.r)
+			if result != tt.expected {
+				t.Errorf("runeWidth(%q) = %d, want %d", tt.r, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCutColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		start, end int
+		unit       string
+		expected   string
+		ok         bool
+	}{
+		{"bytes", "abcdef", 2, 4, args.UnitByte, "bcd", true},
+		{"bytes to end", "abcdef", 4, 0, args.UnitByte, "def", true},
+		{"bytes past end", "abc", 5, 6, args.UnitByte, "", false},
+		{"bytes truncated", "abc", 2, 9, args.UnitByte, "bc", true},
+		{"runes", "жжabc", 2, 3, args.UnitRune, "жa", true},
+		{"runes past end", "жж", 3, 4, args.UnitRune, "", false},
+		{"width after wide characters", "漢字 ab", 6, 7, args.UnitWidth, "ab", true},
+		{"width wide character in range", "漢字 ab", 3, 4, args.UnitWidth, "字", true},
+		{"width range inside a wide character", "漢字 ab", 2, 3, args.UnitWidth, "字", true},
+		{"width combining mark stays", "aéb", 2, 2, args.UnitWidth, "é", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := cutColumns(tt.line, tt.start, tt.end, tt.unit)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("cutColumns(%q, %d, %d) = %q, %v, want %q, %v", tt.line, tt.start, tt.end, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSortFileColumns(t *testing.T) {
+	content := "Smith John   0042 NY\nDoe Jane     0007 LA\nLee Ann      0042 CA\nshort\n"
+
+	tests := []struct {
+		name          string
+		options       *args.KeySort
+		expectedLines []string
+	}{
+		{
+			name: "numeric range with a name containing spaces",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, Keys: []args.Key{
+				{Start: 14, End: 17, Numeric: true},
+				{Start: 1, End: 12},
+			}},
+			expectedLines: []string{"Doe Jane     0007 LA", "Lee Ann      0042 CA", "Smith John   0042 NY", "short"},
+		},
+		{
+			name: "bytes to end of line",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, ColumnsUnit: args.UnitByte, Keys: []args.Key{
+				{Start: 19},
+			}},
+			expectedLines: []string{"Lee Ann      0042 CA", "Doe Jane     0007 LA", "Smith John   0042 NY", "short"},
+		},
+		{
+			name: "missing range last despite --json-missing",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, JSONMissing: args.InvalidFirst, Keys: []args.Key{
+				{Start: 14, End: 17, Numeric: true},
+				{Start: 1, End: 12},
+			}},
+			expectedLines: []string{"Doe Jane     0007 LA", "Lee Ann      0042 CA", "Smith John   0042 NY", "short"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SortFile(openTestFile(t, content), tt.options)
+			if err != nil {
+				t.Fatalf("SortFile() error = %v", err)
+			}
+
+			if len(result) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d lines, got %d", len(tt.expectedLines), len(result))
+			}
+			for i, line := range result {
+				if line != tt.expectedLines[i] {
+					t.Errorf("At index %d: expected %q, got %q", i, tt.expectedLines[i], line)
+				}
+			}
+		})
+	}
+}
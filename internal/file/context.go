@@ -0,0 +1,37 @@
+package file
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader stops reading with the context error once ctx is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(data []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(data)
+}
+
+// Name returns the file name of the wrapped reader, see inputName
+func (r contextReader) Name() string {
+	return inputName(r.reader)
+}
+
+// contextWriter stops writing with the context error once ctx is done
+type contextWriter struct {
+	ctx    context.Context
+	writer io.Writer
+}
+
+func (w contextWriter) Write(data []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.writer.Write(data)
+}
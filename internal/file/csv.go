@@ -0,0 +1,91 @@
+package file
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// csvComma returns the field delimiter for the input format
+func csvComma(options *p.KeySort) rune {
+	if options.TSV {
+		return '\t'
+	}
+	return ','
+}
+
+// readCSV parses RFC 4180 records and re-encodes each of them as the output line
+// Unless --no-header is set the first record is the header: it is returned separately
+// and used to resolve column names in the keys. The returned options hold the resolved keys
+func readCSV(r io.Reader, options *p.KeySort) ([]string, []record, *p.KeySort, error) {
+	comma := csvComma(options)
+
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = options.TSV
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var header []string
+	var headerLines []string
+	if !options.NoHeader && len(rows) > 0 {
+		header = rows[0]
+		headerLines = []string{encodeCSV(header, comma)}
+		rows = rows[1:]
+	}
+
+	resolved, err := resolveColumns(header, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	records := make([]record, len(rows))
+	for i, row := range rows {
+		records[i] = record{line: encodeCSV(row, comma), fields: row}
+	}
+	return headerLines, records, resolved, nil
+}
+
+// resolveColumns returns a copy of options with column names in keys replaced by numbers
+func resolveColumns(header []string, options *p.KeySort) (*p.KeySort, error) {
+	resolved := *options
+	resolved.Keys = make([]p.Key, len(options.Keys))
+
+	for i, key := range options.Keys {
+		if key.ColumnName != "" {
+			index := -1
+			for j, name := range header {
+				if name == key.ColumnName {
+					index = j
+					break
+				}
+			}
+			if index < 0 {
+				return nil, messages.Errorf("%w: %s", p.ErrUnknownColumn, key.ColumnName)
+			}
+			key.ColumnNumber = index + 1
+		}
+		resolved.Keys[i] = key
+	}
+	return &resolved, nil
+}
+
+// encodeCSV writes one record with RFC 4180 quoting, without the trailing newline
+func encodeCSV(fields []string, comma rune) string {
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+	writer.Comma = comma
+
+	// Writing to a strings.Builder cannot fail, and the fields come from a parsed record
+	_ = writer.Write(fields)
+	writer.Flush()
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
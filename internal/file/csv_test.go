@@ -0,0 +1,108 @@
+package file
+
+import (
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"testing"
+)
+
+func TestSortFileCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		options       *args.KeySort
+		expectedLines []string
+		expectError   error
+	}{
+		{
+			name:    "quoted commas by column name",
+			content: "name,city,age\n\"Smith, John\",Paris,42\nAnna,\"Rome, IT\",7\nBob,Oslo,19\n",
+			options: &args.KeySort{SortByColumn: true, CSV: true, Keys: []args.Key{
+				{ColumnName: "age", Numeric: true},
+			}},
+			expectedLines: []string{"name,city,age", "Anna,\"Rome, IT\",7", "Bob,Oslo,19", "\"Smith, John\",Paris,42"},
+		},
+		{
+			name:          "embedded newline by column index",
+			content:       "id,note\n2,\"two\nlines\"\n1,one\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, CSV: true},
+			expectedLines: []string{"id,note", "1,one", "2,\"two\nlines\""},
+		},
+		{
+			name:          "no header with reverse",
+			content:       "a,1\nc,3\nb,2\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 2, CSV: true, NoHeader: true, Reverse: true},
+			expectedLines: []string{"c,3", "b,2", "a,1"},
+		},
+		{
+			name:          "last column from the end",
+			content:       "a,b,3\nc,1\nd,e,f,2\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: -1, CSV: true, NoHeader: true, Numeric: true},
+			expectedLines: []string{"c,1", "d,e,f,2", "a,b,3"},
+		},
+		{
+			name:          "tsv",
+			content:       "name\tsize\nbig\t1M\nsmall\t2K\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 2, TSV: true, HumanNumeric: true},
+			expectedLines: []string{"name\tsize", "small\t2K", "big\t1M"},
+		},
+		{
+			name:    "unknown column name",
+			content: "name,age\nBob,19\n",
+			options: &args.KeySort{SortByColumn: true, CSV: true, Keys: []args.Key{
+				{ColumnName: "city"},
+			}},
+			expectError: args.ErrUnknownColumn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := openTestFile(t, tt.content)
+
+			result, err := SortFile(file, tt.options)
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Errorf("expected error %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SortFile() error = %v", err)
+			}
+
+			if len(result) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d lines, got %d: %q", len(tt.expectedLines), len(result), result)
+			}
+			for i, line := range result {
+				if line != tt.expectedLines[i] {
+					t.Errorf("At index %d: expected %q, got %q", i, tt.expectedLines[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []string
+		comma    rune
+		expected string
+	}{
+		{"plain", []string{"a", "b"}, ',', "a,b"},
+		{"comma in field", []string{"a,b", "c"}, ',', "\"a,b\",c"},
+		{"quote in field", []string{"say \"hi\""}, ',', "\"say \"\"hi\"\"\""},
+		{"newline in field", []string{"a\nb"}, ',', "\"a\nb\""},
+		{"tab separated", []string{"a,b", "c"}, '\t', "a,b\tc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := encodeCSV(tt.fields, tt.comma)
+			if result != tt.expected {
+				t.Errorf("encodeCSV(%q) = %q, want %q", tt.fields, result, tt.expected)
+			}
+		})
+	}
+}
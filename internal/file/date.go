@@ -0,0 +1,163 @@
+package file
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// ErrInvalidDate is returned when a date key cannot be parsed and --date-invalid=error is set
+var ErrInvalidDate = messages.New("invalid date")
+
+// maxDateFields limits how many whitespace-separated fields a date value may span
+const maxDateFields = 6
+
+// dateLayouts are tried in order when no layout is given
+var dateLayouts = []string{
+	"2006-1-2T15:04:05Z07:00", // RFC 3339 and ISO 8601 with offset
+	"2006-1-2T15:04:05Z0700",
+	"2006-1-2T15:04:05",
+	"2006-1-2 15:04:05Z07:00",
+	"2006-1-2 15:04:05 Z07:00",
+	"2006-1-2 15:04:05",
+	"2006-1-2T15:04Z07:00",
+	"2006-1-2T15:04",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700", // RFC 2822 with a one-digit day
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.ANSIC,
+	time.Stamp, // syslog
+}
+
+// parseDate parses s with the given layout, or with the known layouts when layout is empty
+// Values are tried from the longest run of fields down to the first field alone, so a key
+// may hold a date with spaces followed by other text
+func parseDate(s, layout string, location *time.Location) (time.Time, bool) {
+	if location == nil {
+		location = time.Local
+	}
+
+	fields := strings.Fields(s)
+	for n := min(len(fields), maxDateFields); n > 0; n-- {
+		value := strings.Join(fields[:n], " ")
+
+		if layout != "" {
+			if t, err := time.ParseInLocation(layout, value, location); err == nil {
+				return t, true
+			}
+			continue
+		}
+
+		if t, ok := parseEpoch(value, location); ok {
+			return t, true
+		}
+		for _, known := range dateLayouts {
+			if t, err := time.ParseInLocation(known, value, location); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseEpoch parses Unix time in seconds, or in milliseconds when the integer part has more than 11 digits
+func parseEpoch(s string, location *time.Location) (time.Time, bool) {
+	integer, fraction, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if !isDigits(integer) || (fraction != "" && !isDigits(fraction)) {
+		return time.Time{}, false
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if len(integer) > 11 {
+		return time.UnixMilli(int64(value)).In(location), true
+	}
+	seconds := math.Floor(value)
+	return time.Unix(int64(seconds), int64((value-seconds)*1e9)).In(location), true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// dateKeyType is the date key type: every key is parsed once into a time.Time
+// Unparsable values go last unless --date-invalid=first is set
+type dateKeyType struct {
+	layout            string // Layout in Go notation, empty to try the known layouts
+	location          *time.Location
+	placeInvalidFirst bool
+}
+
+// newDateKeyType makes the date key type for layout with the --date-tz and --date-invalid options
+func newDateKeyType(layout string, options *p.KeySort) dateKeyType {
+	return dateKeyType{layout: layout, location: options.DateZone, placeInvalidFirst: options.DateInvalid == p.InvalidFirst}
+}
+
+func (t dateKeyType) Parse(key string) (any, error) {
+	parsed, ok := parseDate(key, t.layout, t.location)
+	if !ok {
+		return nil, ErrInvalidDate
+	}
+	return parsed, nil
+}
+
+func (dateKeyType) Compare(a, b any) int {
+	return a.(time.Time).Compare(b.(time.Time))
+}
+
+func (t dateKeyType) invalidFirst() bool {
+	return t.placeInvalidFirst
+}
+
+func (dateKeyType) spansFields() bool {
+	return true
+}
+
+// checkDates reports the first unparsable date key when --date-invalid=error is set
+// Error line numbers count the offset lines before the first record
+func checkDates(records []record, comparators []keyComparator, offset int, options *p.KeySort) error {
+	if options.DateInvalid != p.InvalidError {
+		return nil
+	}
+
+	for i, rec := range records {
+		if value, ok := invalidDate(rec, comparators); ok {
+			return messages.Errorf("%w: line %d: %s", ErrInvalidDate, offset+i+1, value)
+		}
+	}
+	return nil
+}
+
+// invalidDate returns the first date key of a record with parsed keys that is not a date
+// Typed values other than strings, such as JSON numbers, are parsed as numbers and always valid
+func invalidDate(rec record, comparators []keyComparator) (string, bool) {
+	for k, comparator := range comparators {
+		if _, ok := comparator.keyType.(dateKeyType); ok && rec.keys[k].found && !rec.keys[k].valid {
+			return rec.keys[k].text, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,169 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		layout   string
+		expected time.Time
+		ok       bool
+	}{
+		{"iso date", "2024-12-01", "", time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), true},
+		{"unpadded iso date", "2024-3-5", "", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), true},
+		{"rfc 3339", "2024-03-05T10:00:00+03:00", "", time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC), true},
+		{"iso with space", "2024-03-05 10:20:30 trailing", "", time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC), true},
+		{"rfc 1123", "Tue, 05 Mar 2024 10:00:00 GMT", "", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC), true},
+		{"rfc 2822", "Tue, 5 Mar 2024 10:00:00 +0000", "", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC), true},
+		{"syslog", "Mar  5 10:00:00 host sshd", "", time.Date(0, 3, 5, 10, 0, 0, 0, time.UTC), true},
+		{"epoch seconds", "1709632800", "", time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC), true},
+		{"epoch millis", "1709632800500", "", time.Date(2024, 3, 5, 10, 0, 0, 5e8, time.UTC), true},
+		{"custom layout", "05/03/2024", "02/01/2006", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), true},
+		{"custom layout mismatch", "2024-03-05", "02/01/2006", time.Time{}, false},
+		{"invalid", "yesterday", "", time.Time{}, false},
+		{"empty", "", "", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseDate(tt.input, tt.layout, time.UTC)
+			if ok != tt.ok {
+				t.Fatalf("parseDate(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			}
+			if ok && !result.Equal(tt.expected) {
+				t.Errorf("parseDate(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDateKeyType(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		invalid  string
+		expected int
+	}{
+		{"earlier date", "2024-3-5", "2024-12-01", "", -1},
+		{"later date", "2024-12-01", "2024-3-5", "", 1},
+		{"time zones", "2024-03-05T10:00:00+03:00", "2024-03-05T08:00:00Z", "", -1},
+		{"same instant", "2024-03-05T10:00:00+02:00", "2024-03-05T08:00:00Z", "", 0},
+		{"invalid last", "garbage", "2024-12-01", "", 1},
+		{"valid before invalid", "2024-12-01", "garbage", "", -1},
+		{"invalid first", "garbage", "2024-12-01", args.InvalidFirst, -1},
+		{"both invalid", "abc", "def", "", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyType := newDateKeyType("", &args.KeySort{DateZone: time.UTC, DateInvalid: tt.invalid})
+			a, b := parseKey(keyType, tt.a), parseKey(keyType, tt.b)
+			if result := compareKeys(keyType, a, b); result != tt.expected {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortByDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		options  *args.KeySort
+		expected []string
+	}{
+		{
+			name:     "global date",
+			lines:    []string{"2024-12-01 b", "2024-3-5 a", "2023-01-10 c"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Date: true},
+			expected: []string{"2023-01-10 c", "2024-3-5 a", "2024-12-01 b"},
+		},
+		{
+			name:  "date key with layout",
+			lines: []string{"b 05.03.2024", "a 01.12.2023", "c 10.01.2025"},
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 2, Keys: []args.Key{
+				{ColumnNumber: 2, Date: true, DateLayout: "02.01.2006"},
+			}},
+			expected: []string{"a 01.12.2023", "b 05.03.2024", "c 10.01.2025"},
+		},
+		{
+			name:  "numeric key then date key",
+			lines: []string{"1 2024-12-01", "2 2020-01-01", "1 2024-01-01"},
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, Keys: []args.Key{
+				{ColumnNumber: 1, Numeric: true},
+				{ColumnNumber: 2, Date: true},
+			}},
+			expected: []string{"1 2024-01-01", "1 2024-12-01", "2 2020-01-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([]string, len(tt.lines))
+			copy(lines, tt.lines)
+
+			sortByColumn(lines, tt.options)
+
+			for i, line := range lines {
+				if line != tt.expected[i] {
+					t.Errorf("At index %d: expected %q, got %q", i, tt.expected[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDates(t *testing.T) {
+	options := &args.KeySort{SortByColumn: true, ColumnNumber: 1, Date: true, DateInvalid: args.InvalidError}
+
+	comparators := newComparators(options)
+	records := lineRecords([]string{"2024-01-01", "2024-02-01"})
+	parseKeys(records, comparators, options)
+	if err := checkDates(records, comparators, 0, options); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	records = lineRecords([]string{"2024-01-01", "soon"})
+	parseKeys(records, comparators, options)
+	err := checkDates(records, comparators, 1, options)
+	if !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("expected ErrInvalidDate, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected the line number after the header, got %v", err)
+	}
+}
+
+func TestCheckDatesJSONNumbers(t *testing.T) {
+	options := &args.KeySort{JSONL: true, Keys: []args.Key{{Path: ".ts", Date: true}}, DateInvalid: args.InvalidError}
+	input := "{\"ts\":\"2024-01-01\"}\n{\"ts\":1700000000}\n"
+
+	lines, _, err := Sort(context.Background(), strings.NewReader(input), options)
+	if err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+	if expected := []string{"{\"ts\":1700000000}", "{\"ts\":\"2024-01-01\"}"}; !slices.Equal(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+
+	for _, err := range Merge(context.Background(), []io.Reader{strings.NewReader(input)}, options) {
+		if err != nil {
+			t.Errorf("Merge() error = %v", err)
+		}
+	}
+
+	_, _, err = Sort(context.Background(), strings.NewReader("{\"ts\":1}\n{\"ts\":\"soon\"}\n"), options)
+	if !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("expected ErrInvalidDate, got %v", err)
+	}
+}
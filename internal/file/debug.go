@@ -0,0 +1,217 @@
+package file
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+)
+
+// noMatch marks a key missing from a line in the --debug output
+const noMatch = "^ no match for key"
+
+// Debug sorts r as Sort does and writes every sorted line to w followed by one line per key that
+// underlines the text the key compared, as GNU sort --debug does. A key missing from a line is marked
+// "^ no match for key", followed by the whole line when lines without the key are compared whole
+// Before any output warn is called with every option or key that looks wrong for the input
+func Debug(ctx context.Context, r io.Reader, w io.Writer, warn func(message string), options *p.KeySort) error {
+	in, err := newInput(contextReader{ctx: ctx, reader: r})
+	if err != nil {
+		return err
+	}
+
+	doc, err := readDocument(in, options)
+	if err != nil {
+		return err
+	}
+
+	comparators := doc.comparators
+	for _, warning := range debugWarnings(doc, comparators) {
+		warn(warning)
+	}
+
+	if err := sortKeyed(ctx, doc.records, comparators, doc.options); err != nil {
+		return err
+	}
+	if doc.options.JSON {
+		return WriteLines(ctx, w, []string{doc.array.join(slices.Collect(doc.body()))}, in.format, options)
+	}
+
+	lines := slices.Clone(doc.header)
+	for line := range doc.body() {
+		lines = append(lines, line)
+		if underlined(doc.options) {
+			lines = append(lines, underlines(line, comparators, doc.options)...)
+		}
+	}
+	return WriteLines(ctx, w, append(lines, doc.footer...), in.format, options)
+}
+
+// underlined reports whether the keys of the input format have a place in the line
+func underlined(options *p.KeySort) bool {
+	return splitsOnWhitespace(options) || options.KeyRegex != nil || fixedColumns(options)
+}
+
+// underlines returns the lines that underline every key of line
+func underlines(line string, comparators []keyComparator, options *p.KeySort) []string {
+	var result []string
+	for i, comparator := range comparators {
+		from, to, ok := keySpan(line, i, comparator, options)
+		if !ok || from == to {
+			result = append(result, underline(line, len(line), len(line), options.MessageLanguage().Text(noMatch)))
+			if splitsOnWhitespace(options) && !ok {
+				result = append(result, underline(line, 0, len(line), ""))
+			}
+			continue
+		}
+		result = append(result, underline(line, from, to, ""))
+	}
+	return result
+}
+
+// underline returns the line that puts mark below the bytes from:to of line, or underscores if mark is empty
+// Tabs are kept so the underline lines up with the text in a terminal
+func underline(line string, from, to int, mark string) string {
+	var b strings.Builder
+	for _, r := range line[:from] {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteString(strings.Repeat(" ", runeWidth(r)))
+		}
+	}
+
+	if mark != "" {
+		b.WriteString(mark)
+		return b.String()
+	}
+	for _, r := range line[from:to] {
+		b.WriteString(strings.Repeat("_", runeWidth(r)))
+	}
+	return b.String()
+}
+
+// keySpan returns the byte offsets in line of the text the key at index compares; ok is false when
+// the line has no such key
+func keySpan(line string, index int, comparator keyComparator, options *p.KeySort) (from, to int, ok bool) {
+	switch {
+	case options.KeyRegex != nil:
+		group := regexGroup(options)
+		match := options.KeyRegex.FindStringSubmatchIndex(line)
+		if match == nil || match[2*group] < 0 {
+			return 0, 0, false
+		}
+		return match[2*group], match[2*group+1], true
+	case fixedColumns(options):
+		key := options.Keys[index]
+		from, to, ok = columnSpan(line, key.Start, key.End, columnsUnit(options))
+		if !ok {
+			return 0, 0, false
+		}
+		value := line[from:to]
+		from += len(value) - len(strings.TrimLeftFunc(value, unicode.IsSpace))
+		to -= len(value) - len(strings.TrimRightFunc(value, unicode.IsSpace))
+		return from, max(from, to), true
+	}
+
+	fields := fieldSpans(line)
+	column := comparator.column
+	if column < 0 {
+		column += len(fields)
+	}
+	if column < 0 || column >= len(fields) {
+		return 0, 0, false
+	}
+
+	last := column
+	switch {
+	case comparator.toEnd:
+		last = len(fields) - 1
+	case comparator.columns > 1:
+		last = min(column+comparator.columns, len(fields)) - 1
+	}
+	return fields[column][0], fields[last][1], true
+}
+
+// fieldSpans returns the byte offsets of the whitespace-separated fields of line, the fields strings.Fields returns
+func fieldSpans(line string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range line {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(line)})
+	}
+	return spans
+}
+
+// debugWarnings returns the warnings of --debug about options and keys that look wrong for the records
+func debugWarnings(doc *document, comparators []keyComparator) []string {
+	options := doc.options
+	language := options.MessageLanguage()
+	var warnings []string
+
+	if !underlined(options) {
+		warnings = append(warnings, language.Text("keys of --csv, --tsv, --jsonl and --json input are not underlined"))
+	}
+	if options.SkipBlanks && !options.CSV && !options.TSV {
+		warnings = append(warnings, language.Text("option '-b' has no effect: only --csv and --tsv fields can start with blanks"))
+	}
+
+	for i, key := range sortKeys(options) {
+		comparator := comparators[i]
+		name, _ := key.KeyTypeName()
+		if comparator.columns > 1 && name != "lexical" && name != "natural" {
+			warnings = append(warnings, language.Sprintf("key %d is %s and spans multiple fields", i+1, name))
+		}
+
+		present, valid := keyValues(doc.records, comparator, keyTypeValid(newKeyType(key, options)), options)
+		switch {
+		case len(doc.records) > 0 && !present:
+			warnings = append(warnings, language.Sprintf("key %d is missing from every line", i+1))
+		case present && !valid:
+			warnings = append(warnings, language.Sprintf("key %d has no valid %s value in any line; the values are compared as text", i+1, name))
+		}
+	}
+	return warnings
+}
+
+// keyValues reports whether any record has the key, and whether any of those values is valid
+// Typed values other than strings, such as JSON numbers, are valid for every key type
+func keyValues(records []record, comparator keyComparator, valid func(key string) bool, options *p.KeySort) (bool, bool) {
+	present := false
+	for _, rec := range records {
+		var value string
+		if rec.kinds != nil {
+			kind := rec.kinds[comparator.column]
+			if kind == kindMissing {
+				continue
+			}
+			if kind != kindString {
+				return true, true
+			}
+			value = rec.fields[comparator.column]
+		} else {
+			var ok bool
+			if value, ok = comparator.value(rec.fields, options); !ok {
+				continue
+			}
+		}
+
+		present = true
+		if valid(value) {
+			return true, true
+		}
+	}
+	return present, false
+}
@@ -0,0 +1,128 @@
+package file
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rzmsq/sort_utility/internal/args"
+)
+
+func TestDebug(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		options          *args.KeySort
+		expectedOutput   string
+		expectedWarnings []string
+	}{
+		{
+			name:           "whole line",
+			input:          "b\na c\n",
+			options:        &args.KeySort{SortByColumn: true, ColumnNumber: 1},
+			expectedOutput: "a c\n_\nb\n_\n",
+		},
+		{
+			name:           "column range with tab",
+			input:          "x\t20 y\nx 3 z\n",
+			options:        &args.KeySort{SortByColumn: true, ColumnNumber: 2, Keys: []args.Key{{ColumnNumber: 2, EndColumn: 3}}},
+			expectedOutput: "x\t20 y\n \t____\nx 3 z\n  ___\n",
+		},
+		{
+			name:           "missing column",
+			input:          "a 2\nb\n",
+			options:        &args.KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true},
+			expectedOutput: "a 2\n  _\nb\n ^ no match for key\n_\n",
+		},
+		{
+			name:           "wide characters and regexp",
+			input:          "日本 id=7\nab id=10\n",
+			options:        &args.KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true, KeyRegex: regexp.MustCompile(`id=(\d+)`)},
+			expectedOutput: "日本 id=7\n        _\nab id=10\n      __\n",
+		},
+		{
+			name:           "fixed columns",
+			input:          "ab  x\ncd y \n",
+			options:        &args.KeySort{SortByColumn: true, ColumnNumber: 1, Keys: []args.Key{{Start: 3, End: 5}}},
+			expectedOutput: "ab  x\n    _\ncd y \n   _\n",
+		},
+		{
+			name:             "key that never parses",
+			input:            "b x\na y\n",
+			options:          &args.KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true},
+			expectedOutput:   "b x\n  _\na y\n  _\n",
+			expectedWarnings: []string{"key 1 has no valid numeric value in any line; the values are compared as text"},
+		},
+		{
+			name:           "blanks and multiple numeric fields",
+			input:          "1 2\n",
+			options:        &args.KeySort{SortByColumn: true, ColumnNumber: 1, SkipBlanks: true, Keys: []args.Key{{ColumnNumber: 1, EndColumn: 2, Numeric: true}}},
+			expectedOutput: "1 2\n___\n",
+			expectedWarnings: []string{
+				"option '-b' has no effect: only --csv and --tsv fields can start with blanks",
+				"key 1 is numeric and spans multiple fields",
+				"key 1 has no valid numeric value in any line; the values are compared as text",
+			},
+		},
+		{
+			name:             "key missing from every line",
+			input:            "b\na\n",
+			options:          &args.KeySort{SortByColumn: true, ColumnNumber: 3},
+			expectedOutput:   "a\n ^ no match for key\n_\nb\n ^ no match for key\n_\n",
+			expectedWarnings: []string{"key 1 is missing from every line"},
+		},
+		{
+			name:             "CSV",
+			input:            "n\n2\n1\n",
+			options:          &args.KeySort{SortByColumn: true, ColumnNumber: 1, CSV: true, Numeric: true},
+			expectedOutput:   "n\n1\n2\n",
+			expectedWarnings: []string{"keys of --csv, --tsv, --jsonl and --json input are not underlined"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			var warnings []string
+			warn := func(message string) { warnings = append(warnings, message) }
+
+			if err := Debug(context.Background(), strings.NewReader(tt.input), &output, warn, tt.options); err != nil {
+				t.Fatalf("Debug() error = %v", err)
+			}
+			if output.String() != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, output.String())
+			}
+			if !reflect.DeepEqual(warnings, tt.expectedWarnings) {
+				t.Errorf("expected warnings %q, got %q", tt.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestFieldSpans(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected [][2]int
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"a", [][2]int{{0, 1}}},
+		{" ab\tc ", [][2]int{{1, 3}, {4, 5}}},
+		{"я б", [][2]int{{0, 2}, {3, 5}}},
+	}
+
+	for _, tt := range tests {
+		spans := fieldSpans(tt.line)
+		if !reflect.DeepEqual(spans, tt.expected) {
+			t.Errorf("fieldSpans(%q) = %v, expected %v", tt.line, spans, tt.expected)
+		}
+		fields := strings.Fields(tt.line)
+		for i, span := range spans {
+			if tt.line[span[0]:span[1]] != fields[i] {
+				t.Errorf("fieldSpans(%q) field %d is %q, strings.Fields gives %q", tt.line, i, tt.line[span[0]:span[1]], fields[i])
+			}
+		}
+	}
+}
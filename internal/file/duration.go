@@ -0,0 +1,108 @@
+package file
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits maps unit suffixes to their length; d and w extend time.ParseDuration
+var durationUnits = map[string]float64{
+	"ns": float64(time.Nanosecond),
+	"us": float64(time.Microsecond),
+	"µs": float64(time.Microsecond), // U+00B5 micro sign
+	"μs": float64(time.Microsecond), // U+03BC Greek letter mu
+	"ms": float64(time.Millisecond),
+	"s":  float64(time.Second),
+	"m":  float64(time.Minute),
+	"h":  float64(time.Hour),
+	"d":  float64(24 * time.Hour),
+	"w":  float64(7 * 24 * time.Hour),
+}
+
+// parseDuration parses a duration in nanoseconds
+// It accepts time.ParseDuration syntax with the extra units d and w ("1w2d", "1h30m", "2.5s")
+// and clock durations HH:MM:SS or MM:SS with optional fractional seconds
+func parseDuration(s string) (float64, bool) {
+	sign := 1.0
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		sign, s = -1, rest
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	var value float64
+	var ok bool
+	if strings.Contains(s, ":") {
+		value, ok = parseClockDuration(s)
+	} else {
+		value, ok = parseUnitDuration(s)
+	}
+	return sign * value, ok
+}
+
+// parseClockDuration parses HH:MM:SS or MM:SS
+func parseClockDuration(s string) (float64, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+
+	var total float64
+	for i, part := range parts {
+		last := i == len(parts)-1
+		if part == "" || (!last && !isDigits(part)) {
+			return 0, false
+		}
+
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 || part[0] == '.' {
+			return 0, false
+		}
+		// Minutes and seconds after the leading part must stay below 60
+		if i > 0 && value >= 60 {
+			return 0, false
+		}
+		total = total*60 + value
+	}
+	return total * float64(time.Second), true
+}
+
+// parseUnitDuration parses a sequence of decimal numbers with unit suffixes
+func parseUnitDuration(s string) (float64, bool) {
+	if s == "0" {
+		return 0, true
+	}
+	if s == "" {
+		return 0, false
+	}
+
+	var total float64
+	for s != "" {
+		end := 0
+		for end < len(s) && (isDigit(s[end]) || s[end] == '.') {
+			end++
+		}
+		if end == 0 {
+			return 0, false
+		}
+		number, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			return 0, false
+		}
+		s = s[end:]
+
+		end = 0
+		for end < len(s) && !isDigit(s[end]) && s[end] != '.' {
+			end++
+		}
+		unit, ok := durationUnits[s[:end]]
+		if !ok {
+			return 0, false
+		}
+		s = s[end:]
+
+		total += number * unit
+	}
+	return total, true
+}
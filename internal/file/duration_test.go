@@ -0,0 +1,69 @@
+package file
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"go syntax", "1h30m", 90 * time.Minute, true},
+		{"milliseconds", "250ms", 250 * time.Millisecond, true},
+		{"fractional seconds", "2.5s", 2500 * time.Millisecond, true},
+		{"microseconds", "15µs", 15 * time.Microsecond, true},
+		{"days", "3d", 72 * time.Hour, true},
+		{"weeks and days", "1w2d", 9 * 24 * time.Hour, true},
+		{"negative", "-1m", -time.Minute, true},
+		{"zero", "0", 0, true},
+		{"clock", "01:30:00", 90 * time.Minute, true},
+		{"clock with long hours", "100:00:01", 100*time.Hour + time.Second, true},
+		{"minutes and seconds", "02:05", 125 * time.Second, true},
+		{"clock with fraction", "00:00:01.5", 1500 * time.Millisecond, true},
+		{"clock minutes out of range", "01:75:00", 0, false},
+		{"number without unit", "15", 0, false},
+		{"unknown unit", "5y", 0, false},
+		{"empty", "", 0, false},
+		{"text", "fast", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseDuration(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("parseDuration(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			}
+			if ok && time.Duration(result) != tt.expected {
+				t.Errorf("parseDuration(%q) = %v, want %v", tt.input, time.Duration(result), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"milliseconds before seconds", "250ms", "2.5s", true},
+		{"hours before days", "1h30m", "3d", true},
+		{"clock and go syntax", "00:10:00", "1h", true},
+		{"reverse order", "1w", "6d", false},
+		{"invalid last", "n/a", "1s", false},
+		{"valid before invalid", "1s", "n/a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := keyLess(t, "duration", tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("compareDuration(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,22 +1,23 @@
 package file
 
 import (
-	"bufio"
+	"context"
 	"errors"
-	"fmt"
+	"io"
+	"iter"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 
-	p "sort_utility/internal/args"
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
 )
 
-func writeMsg(str []byte) {
-	_, err := os.Stdout.Write(str)
-	if err != nil {
-		panic(err)
-	}
+func writeMsg(w io.Writer, str []byte) error {
+	_, err := w.Write(str)
+	return err
 }
 
 // OpenFile attempts to open the file at the given filepath
@@ -27,190 +28,637 @@ func OpenFile(filepath string) (*os.File, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("cannot read: %s: %w", filepath, p.ErrFileNotFound)
+			return nil, messages.Errorf("cannot read: %s: %w", filepath, p.ErrFileNotFound)
 		}
-		return nil, fmt.Errorf("failed to open file %s: %w", filepath, err)
+		return nil, messages.Errorf("failed to open file %s: %w", filepath, err)
 	}
 	return file, nil
 }
 
+// record is one input record: the text written to the output and the fields keys are taken from
+// Typed records (JSON, --key-regex and --columns) hold one field per key together with the kind of each value
+type record struct {
+	line   string
+	fields []string
+	kinds  []valueKind
+	keys   []keyValue // Parsed value of every sort key, set by parseKeys before comparing
+}
+
+// keyValue is the value of one sort key of a record, parsed once before sorting
+type keyValue struct {
+	text  string
+	value any  // Result of the key type's Parse
+	valid bool // Parse succeeded
+	found bool // The record has the key; false for a missing column and for typed values other than strings and numbers
+}
+
+// valueKind is the type of a typed key value; values of different kinds are ordered by kind
+type valueKind int
+
+const (
+	kindMissing   valueKind = iota // null, a missing member, no --key-regex match or no --columns text
+	kindBool                       // false before true
+	kindNumber                     // compared numerically
+	kindString                     // compared with the key type
+	kindComposite                  // objects and arrays, compared as compact JSON text
+)
+
+// lineRecords splits every line into whitespace-separated fields
+func lineRecords(lines []string) []record {
+	records := make([]record, len(lines))
+	for i, line := range lines {
+		records[i] = record{line: line, fields: strings.Fields(line)}
+	}
+	return records
+}
+
+// recordLines returns the output text of the records
+func recordLines(records []record) []string {
+	lines := make([]string, len(records))
+	for i, rec := range records {
+		lines[i] = rec.line
+	}
+	return lines
+}
+
 // SortFile reads lines from the provided file and sorts them based on the given options
 // It supports checking if the file is already sorted, sorting by column, removing duplicates,
 // and reversing the result. Returns the sorted lines or an error
+// In CSV and TSV mode the lines are re-encoded records and the header row stays first,
+// in JSON mode the only line is the array document with its elements sorted
+// The first --header and the last --footer records are neither sorted nor checked
 func SortFile(file *os.File, options *p.KeySort) ([]string, error) {
-	var lines []string
-	scanner := bufio.NewScanner(file)
+	lines, _, err := SortFileFormat(file, options)
+	return lines, err
+}
 
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+// SortFileFormat works as SortFile and also returns the line endings, byte order mark
+// and final newline of the file, which WriteLines uses to write the lines back
+func SortFileFormat(file *os.File, options *p.KeySort) ([]string, Format, error) {
+	ctx := context.Background()
+	if options.IsSorted {
+		sorted, err := Check(ctx, file, options)
+		if err != nil {
+			return nil, Format{}, err
+		}
+		return nil, Format{}, WriteCheckResult(os.Stdout, sorted, options.MessageLanguage())
 	}
+	return Sort(ctx, file, options)
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// WriteCheckResult writes the result of check mode to w in language
+func WriteCheckResult(w io.Writer, sorted bool, language messages.Language) error {
+	if sorted {
+		return writeMsg(w, []byte(language.Text("File is sorted")+"\n"))
 	}
+	return writeMsg(w, []byte(language.Text("File is not sorted")+"\n"))
+}
 
-	if options.IsSorted {
-		if isSorted(lines, options) {
-			writeMsg([]byte("Файл отсортирован\n"))
+// Sort reads records from r and returns them sorted together with the format of the input,
+// see SortFile. Check mode is not applied here, use Check for it
+// The byte order mark is not part of the first line and "\r" is not part of any line
+// Once ctx is done reading and sorting stop and the context error is returned
+func Sort(ctx context.Context, r io.Reader, options *p.KeySort) ([]string, Format, error) {
+	in, err := newInput(contextReader{ctx: ctx, reader: r})
+	if err != nil {
+		return nil, Format{}, err
+	}
 
-		} else {
-			writeMsg([]byte("Файл не отсортирован\n"))
+	doc, err := readDocument(in, options)
+	if err != nil {
+		return nil, Format{}, err
+	}
+
+	lines, err := doc.sort(ctx)
+	if err != nil {
+		return nil, Format{}, err
+	}
+	return lines, in.format, nil
+}
+
+// All reads records from r and yields them sorted one line at a time, see Sort
+// The records are held in memory, but the output lines are not collected
+func All(ctx context.Context, r io.Reader, options *p.KeySort) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		in, err := newInput(contextReader{ctx: ctx, reader: r})
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		doc, err := readDocument(in, options)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		for line, err := range doc.all(ctx) {
+			if !yield(line, err) || err != nil {
+				return
+			}
 		}
+	}
+}
+
+// Check reads records from r and reports whether they are already sorted
+// Once ctx is done reading stops and the context error is returned
+func Check(ctx context.Context, r io.Reader, options *p.KeySort) (bool, error) {
+	disorder, err := FirstDisorder(ctx, r, options)
+	return disorder == nil && err == nil, err
+}
+
+// Disorder is the first record of an input that is out of order
+type Disorder struct {
+	Line int    // Number of the record in the input, counting the header and from 1
+	Text string // Output text of the record
+}
+
+// FirstDisorder reads records from r and returns the first one that is out of order,
+// or nil when they are sorted. Once ctx is done reading stops and the context error is returned
+func FirstDisorder(ctx context.Context, r io.Reader, options *p.KeySort) (*Disorder, error) {
+	in, err := newInput(contextReader{ctx: ctx, reader: r})
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := readDocument(in, options)
+	if err != nil {
+		return nil, err
+	}
+
+	i := firstDisorder(doc.records, doc.comparators, doc.options)
+	if i < 0 {
 		return nil, nil
 	}
+	return &Disorder{Line: len(doc.header) + i + 1, Text: doc.records[i].line}, nil
+}
 
-	sortByColumn(lines, options)
+// document is the input split into the records to sort and the lines kept in place
+type document struct {
+	header      []string        // CSV header row and --header lines
+	records     []record        // Records that are sorted, with their keys parsed
+	footer      []string        // --footer lines
+	array       jsonArray       // Layout of a JSON array document
+	options     *p.KeySort      // Options with the keys resolved for the input format
+	comparators []keyComparator // Comparators of the resolved keys
+}
 
-	if options.Unique {
-		lines = removeDuplicates(lines)
+// readDocument reads all records of in in the input format of options
+func readDocument(in *input, options *p.KeySort) (*document, error) {
+	var header, footer []string
+	var records []record
+	var array jsonArray
+
+	var err error
+	switch {
+	case options.CSV || options.TSV:
+		header, records, options, err = readCSV(in.reader, options)
+	case options.JSON:
+		array, records, options, err = readJSONArray(in, options)
+	default:
+		var lines []string
+		lines, err = in.readLines(options)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only the body lines are records, the header and footer may be in any format
+		var body []string
+		header, body, footer = splitHeaderFooter(lines, options)
+
+		switch {
+		case options.JSONL:
+			records, options, err = jsonlRecords(body, len(header), options)
+		case options.KeyRegex != nil:
+			records, err = regexRecords(body, len(header), options)
+		case fixedColumns(options):
+			records, options = columnRecords(body, options)
+		default:
+			records = lineRecords(body)
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if options.Reverse {
-		reverseSlice(lines)
+	if options.CSV || options.TSV || options.JSON {
+		head, body, foot := splitHeaderFooter(records, options)
+		header = append(header, recordLines(head)...)
+		records, footer = body, recordLines(foot)
 	}
 
-	return lines, nil
+	comparators := newComparators(options)
+	parseKeys(records, comparators, options)
+
+	if err := checkDates(records, comparators, len(header), options); err != nil {
+		return nil, err
+	}
+
+	return &document{
+		header:      header,
+		records:     records,
+		footer:      footer,
+		array:       array,
+		options:     options,
+		comparators: comparators,
+	}, nil
 }
 
-func isSorted(lines []string, options *p.KeySort) bool {
-	if len(lines) <= 1 {
-		return true
+// sort sorts the records and returns the output lines
+func (d *document) sort(ctx context.Context) ([]string, error) {
+	if err := sortKeyed(ctx, d.records, d.comparators, d.options); err != nil {
+		return nil, err
 	}
 
-	for i := 0; i < len(lines)-1; i++ {
-		fieldsI := strings.Fields(lines[i])
-		fieldsJ := strings.Fields(lines[i+1])
+	records := d.records
+	if d.options.Unique {
+		records = removeDuplicates(records, d.comparators, d.options)
+	}
+	lines := recordLines(records)
 
-		colIndex := options.ColumnNumber - 1
-		if colIndex >= len(fieldsI) || colIndex >= len(fieldsJ) {
-			if lines[i] > lines[i+1] {
-				return false
-			}
-			continue
+	if d.options.Reverse {
+		reverseSlice(lines)
+	}
+
+	if d.options.JSON {
+		return []string{d.array.join(lines)}, nil
+	}
+	lines = append(d.header, lines...)
+	return append(lines, d.footer...), nil
+}
+
+// all sorts the records and yields the output lines one at a time, as sort returns them
+func (d *document) all(ctx context.Context) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if err := sortKeyed(ctx, d.records, d.comparators, d.options); err != nil {
+			yield("", err)
+			return
 		}
 
-		valueI := fieldsI[colIndex]
-		valueJ := fieldsJ[colIndex]
+		if d.options.JSON {
+			yield(d.array.join(slices.Collect(d.body())), nil)
+			return
+		}
 
-		if options.SkipBlanks {
-			valueI = strings.TrimSpace(valueI)
-			valueJ = strings.TrimSpace(valueJ)
+		for _, line := range d.header {
+			if !yield(line, nil) {
+				return
+			}
+		}
+		for line := range d.body() {
+			if !yield(line, nil) {
+				return
+			}
+		}
+		for _, line := range d.footer {
+			if !yield(line, nil) {
+				return
+			}
 		}
+	}
+}
 
-		var greater bool
-		if options.Numeric {
-			greater = !compareNumeric(valueI, valueJ) && valueI != valueJ
-		} else if options.Month {
-			greater = !compareMonth(valueI, valueJ) && valueI != valueJ
-		} else if options.HumanNumeric {
-			greater = !compareHumanNumeric(valueI, valueJ) && valueI != valueJ
-		} else {
-			greater = valueI > valueJ
+// body yields the lines of the sorted records, backwards with -r and without repeated keys with -u
+func (d *document) body() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		records := d.records
+		if d.options.Unique {
+			records = removeDuplicates(records, d.comparators, d.options)
 		}
 
-		if greater {
-			return false
+		for i := range records {
+			index := i
+			if d.options.Reverse {
+				index = len(records) - 1 - i
+			}
+			if !yield(records[index].line) {
+				return
+			}
 		}
 	}
-	return true
+}
+
+// splitHeaderFooter separates the --header and --footer lines or records, which keep their place
+func splitHeaderFooter[T any](items []T, options *p.KeySort) ([]T, []T, []T) {
+	headerLines := min(options.HeaderLines, len(items))
+	footerLines := min(options.FooterLines, len(items)-headerLines)
+
+	body := items[headerLines : len(items)-footerLines]
+	return items[:headerLines], body, items[len(items)-footerLines:]
+}
+
+func isSorted(lines []string, options *p.KeySort) bool {
+	return recordsSorted(lineRecords(lines), options)
 }
 
 func sortByColumn(lines []string, options *p.KeySort) {
-	if isSorted(lines, options) {
-		return
-	}
+	records := lineRecords(lines)
+	sortRecords(records, options)
+	copy(lines, recordLines(records))
+}
+
+func recordsSorted(records []record, options *p.KeySort) bool {
+	comparators := newComparators(options)
+	parseKeys(records, comparators, options)
+	return keysSorted(records, comparators, options)
+}
 
-	sort.Slice(lines, func(i, j int) bool {
-		fieldsI := strings.Fields(lines[i])
-		fieldsJ := strings.Fields(lines[j])
+// keysSorted reports whether records with parsed keys are in order
+func keysSorted(records []record, comparators []keyComparator, options *p.KeySort) bool {
+	return firstDisorder(records, comparators, options) < 0
+}
 
-		colIndex := options.ColumnNumber - 1
-		if colIndex >= len(fieldsI) || colIndex >= len(fieldsJ) {
-			return lines[i] < lines[j]
+// firstDisorder returns the index of the first record with parsed keys that sorts before
+// the record preceding it, or -1 when the records are in order
+func firstDisorder(records []record, comparators []keyComparator, options *p.KeySort) int {
+	for i := 1; i < len(records); i++ {
+		if compareRecords(records[i-1], records[i], comparators, options) > 0 {
+			return i
 		}
+	}
+	return -1
+}
 
-		valueI := fieldsI[colIndex]
-		valueJ := fieldsJ[colIndex]
+func sortRecords(records []record, options *p.KeySort) {
+	_ = sortRecordsContext(context.Background(), records, options)
+}
 
-		if options.SkipBlanks {
-			valueI = strings.TrimSpace(valueI)
-			valueJ = strings.TrimSpace(valueJ)
-		}
+// sortRecordsContext sorts like sortRecords; once ctx is done the remaining
+// comparisons are skipped and the context error is returned
+func sortRecordsContext(ctx context.Context, records []record, options *p.KeySort) error {
+	comparators := newComparators(options)
+	parseKeys(records, comparators, options)
+	return sortKeyed(ctx, records, comparators, options)
+}
 
-		if options.Numeric {
-			return compareNumeric(valueI, valueJ)
-		}
+// sortKeyed sorts records whose keys are parsed for comparators, see sortRecordsContext
+// With -u the sort is stable, so the first of the records with equal keys is the one kept,
+// and records are ordered as compareUnique does, so that equal lines end up together
+func sortKeyed(ctx context.Context, records []record, comparators []keyComparator, options *p.KeySort) error {
+	compare := compareRecords
+	sortSlice := sort.Slice
+	if options.Unique {
+		compare, sortSlice = compareUnique, sort.SliceStable
+	}
+	less := func(i, j int) bool {
+		return compare(records[i], records[j], comparators, options) < 0
+	}
+	if sort.SliceIsSorted(records, less) {
+		return nil
+	}
 
-		if options.Month {
-			return compareMonth(valueI, valueJ)
+	done := ctx.Done()
+	var err error
+	sortSlice(records, func(i, j int) bool {
+		if err != nil {
+			return false
 		}
-
-		if options.HumanNumeric {
-			return compareHumanNumeric(valueI, valueJ)
+		select {
+		case <-done:
+			err = ctx.Err()
+			return false
+		default:
 		}
-
-		return valueI < valueJ
+		return less(i, j)
 	})
+	return err
 }
 
-func compareNumeric(a, b string) bool {
-	numA, errA := strconv.ParseFloat(a, 64)
-	numB, errB := strconv.ParseFloat(b, 64)
+// keyComparator compares the values of one sort key
+type keyComparator struct {
+	column       int       // Zero-based column index, negative counts from the end (-1 is the last)
+	columns      int       // Number of columns in the value of a -k F1,F2 key, 0 for one column
+	toEnd        bool      // The value runs from the column to the end of the record
+	reverse      bool      // The key sorts in descending order
+	missingFirst bool      // Missing typed values go before the others, see missingFirst
+	keyType      p.KeyType // Parses and orders the key values
+}
+
+// keyColumn converts the 1-based key column to a comparator column; negative columns count from the end
+func keyColumn(key p.Key) int {
+	if key.ColumnNumber < 0 {
+		return key.ColumnNumber
+	}
+	return key.ColumnNumber - 1
+}
+
+// value returns the key value taken from the fields of a record
+func (c keyComparator) value(fields []string, options *p.KeySort) (string, bool) {
+	column := c.column
+	if column < 0 {
+		column += len(fields)
+	}
+	if column < 0 || column >= len(fields) {
+		return "", false
+	}
+
+	if c.toEnd {
+		return strings.Join(fields[column:], " "), true
+	}
+	if c.columns > 1 {
+		return strings.Join(fields[column:min(column+c.columns, len(fields))], " "), true
+	}
+
+	value := fields[column]
+	if options.SkipBlanks {
+		value = strings.TrimSpace(value)
+	}
+	return value, true
+}
+
+// parse returns the parsed key of a record
+func (c keyComparator) parse(rec record, options *p.KeySort) keyValue {
+	var text string
+	if rec.kinds != nil {
+		if c.column < 0 || c.column >= len(rec.kinds) {
+			return keyValue{}
+		}
+		switch rec.kinds[c.column] {
+		case kindNumber:
+			return parseKey(numericKeyType, rec.fields[c.column])
+		case kindString:
+			text = rec.fields[c.column]
+		default:
+			return keyValue{}
+		}
+	} else {
+		var found bool
+		if text, found = c.value(rec.fields, options); !found {
+			return keyValue{}
+		}
+	}
+	return parseKey(c.keyType, text)
+}
 
-	if errA != nil && errB != nil {
-		return a < b
+// parseKeys parses the keys of every record once, so that comparisons only compare parsed values
+func parseKeys(records []record, comparators []keyComparator, options *p.KeySort) {
+	for i := range records {
+		records[i].keys = recordKeys(records[i], comparators, options)
 	}
-	if errA != nil {
-		return false
+}
+
+// recordKeys returns the parsed keys of one record
+func recordKeys(rec record, comparators []keyComparator, options *p.KeySort) []keyValue {
+	keys := make([]keyValue, len(comparators))
+	for k, comparator := range comparators {
+		keys[k] = comparator.parse(rec, options)
 	}
-	if errB != nil {
-		return true
+	return keys
+}
+
+// splitsOnWhitespace reports whether record fields are whitespace-separated words of a line
+func splitsOnWhitespace(options *p.KeySort) bool {
+	return !options.CSV && !options.TSV && !options.JSONL && !options.JSON && options.KeyRegex == nil && !fixedColumns(options)
+}
+
+// sortKeys returns the keys to sort by, in priority order
+// Keys without their own type inherit the global sort type
+func sortKeys(options *p.KeySort) []p.Key {
+	keys := options.Keys
+	if len(keys) == 0 {
+		keys = []p.Key{{ColumnNumber: options.ColumnNumber}}
 	}
 
-	return numA < numB
+	result := make([]p.Key, len(keys))
+	for i, key := range keys {
+		if !key.HasType() {
+			key.Numeric = options.Numeric
+			key.Month = options.Month
+			key.HumanNumeric = options.HumanNumeric
+			key.Date = options.Date
+			key.DateLayout = options.DateLayout
+			key.IP = options.IP
+			key.SemVer = options.SemVer
+			key.Natural = options.Natural
+			key.Duration = options.Duration
+			key.Type = options.Type
+			key.TypeArg = options.TypeArg
+		}
+		result[i] = key
+	}
+	return result
 }
 
-func compareMonth(a, b string) bool {
-	monthOrder := map[string]int{
-		"jan": 1, "january": 1,
-		"feb": 2, "february": 2,
-		"mar": 3, "march": 3,
-		"apr": 4, "april": 4,
-		"may": 5,
-		"jun": 6, "june": 6,
-		"jul": 7, "july": 7,
-		"aug": 8, "august": 8,
-		"sep": 9, "september": 9,
-		"oct": 10, "october": 10,
-		"nov": 11, "november": 11,
-		"dec": 12, "december": 12,
+// newComparators builds one comparator per sort key from the registered key types
+func newComparators(options *p.KeySort) []keyComparator {
+	keys := sortKeys(options)
+
+	comparators := make([]keyComparator, 0, len(keys))
+	for _, key := range keys {
+		keyType := newKeyType(key, options)
+
+		comparator := keyComparator{column: keyColumn(key), reverse: key.Reverse, missingFirst: missingFirst(options), keyType: keyType}
+		if key.EndColumn > key.ColumnNumber {
+			comparator.columns = key.EndColumn - key.ColumnNumber + 1
+		}
+		if spanning, ok := keyType.(fieldSpanner); ok && spanning.spansFields() {
+			comparator.toEnd = splitsOnWhitespace(options)
+		}
+		comparators = append(comparators, comparator)
 	}
+	return comparators
+}
 
-	orderA, okA := monthOrder[strings.ToLower(a)]
-	orderB, okB := monthOrder[strings.ToLower(b)]
+// compareRecords compares two records with parsed keys key by key and returns -1, 0 or +1
+// If a key column is missing in either record the whole lines are compared
+func compareRecords(recordI, recordJ record, comparators []keyComparator, options *p.KeySort) int {
+	for k, comparator := range comparators {
+		var c int
+		if recordI.kinds != nil {
+			c = compareTyped(recordI, recordJ, k, comparator)
+		} else {
+			keyI, keyJ := recordI.keys[k], recordJ.keys[k]
+			if !keyI.found || !keyJ.found {
+				return strings.Compare(recordI.line, recordJ.line)
+			}
+			c = compareKeys(comparator.keyType, keyI, keyJ)
+		}
 
-	if !okA && !okB {
-		return a < b
+		if comparator.reverse {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
 	}
-	if !okA {
-		return false
+	return 0
+}
+
+// missingFirst reports whether the input format puts missing typed values first: null and missing
+// JSON values with --json-missing=first, unmatched lines with --key-regex-nomatch=first
+// Missing --columns ranges always go last
+func missingFirst(options *p.KeySort) bool {
+	switch {
+	case options.JSONL || options.JSON:
+		return options.JSONMissing == p.InvalidFirst
+	case options.KeyRegex != nil:
+		return options.KeyRegexNoMatch == p.InvalidFirst
 	}
-	if !okB {
-		return true
+	return false
+}
+
+// compareTyped compares key k of two typed records and returns -1, 0 or +1
+// Missing values are placed as the comparator says
+func compareTyped(recordI, recordJ record, k int, comparator keyComparator) int {
+	column := comparator.column
+	kindI, kindJ := recordI.kinds[column], recordJ.kinds[column]
+
+	if kindI != kindJ {
+		if kindI == kindMissing || kindJ == kindMissing {
+			if (kindI == kindMissing) == comparator.missingFirst {
+				return -1
+			}
+			return 1
+		}
+		if kindI < kindJ {
+			return -1
+		}
+		return 1
 	}
 
-	return orderA < orderB
+	switch kindI {
+	case kindMissing:
+		return 0
+	case kindNumber:
+		return compareKeys(numericKeyType, recordI.keys[k], recordJ.keys[k])
+	case kindString:
+		return compareKeys(comparator.keyType, recordI.keys[k], recordJ.keys[k])
+	}
+	return strings.Compare(recordI.fields[column], recordJ.fields[column])
 }
 
-func compareHumanNumeric(a, b string) bool {
-	valueA := parseHumanNumeric(a)
-	valueB := parseHumanNumeric(b)
-	return valueA < valueB
+// parseNumber parses a numeric key, the value of typed numbers such as JSON numbers too
+func parseNumber(key string) (float64, bool) {
+	value, err := strconv.ParseFloat(key, 64)
+	return value, err == nil
 }
 
-func parseHumanNumeric(s string) float64 {
+// monthOrder is the number of every month name and abbreviation, in lower case
+var monthOrder = map[string]int{
+	"jan": 1, "january": 1,
+	"feb": 2, "february": 2,
+	"mar": 3, "march": 3,
+	"apr": 4, "april": 4,
+	"may": 5,
+	"jun": 6, "june": 6,
+	"jul": 7, "july": 7,
+	"aug": 8, "august": 8,
+	"sep": 9, "september": 9,
+	"oct": 10, "october": 10,
+	"nov": 11, "november": 11,
+	"dec": 12, "december": 12,
+}
+
+// parseMonth returns the number of a month name or abbreviation in any case
+func parseMonth(key string) (int, bool) {
+	month, ok := monthOrder[strings.ToLower(key)]
+	return month, ok
+}
+
+// parseHumanNumeric parses a number with an optional K, M, G or T suffix of powers of 1024
+func parseHumanNumeric(s string) (float64, bool) {
 	if len(s) == 0 {
-		return 0
+		return 0, false
 	}
 
 	suffix := strings.ToUpper(string(s[len(s)-1]))
@@ -236,23 +684,39 @@ func parseHumanNumeric(s string) float64 {
 
 	num, err := strconv.ParseFloat(numStr, 64)
 	if err != nil {
-		return 0
+		return 0, false
 	}
 
-	return num * multiplier
+	return num * multiplier, true
+}
+
+// compareUnique compares records as -u does: by explicit keys alone, otherwise by the
+// keys and then the whole lines, so that only equal lines are duplicates
+func compareUnique(recordI, recordJ record, comparators []keyComparator, options *p.KeySort) int {
+	if c := compareRecords(recordI, recordJ, comparators, options); c != 0 || explicitKeys(options) {
+		return c
+	}
+	return strings.Compare(recordI.line, recordJ.line)
+}
+
+// explicitKeys reports whether the keys are given with -k, --key-from-end, --columns, --key-regex
+// or as JSON paths, rather than being the first field of the line
+func explicitKeys(options *p.KeySort) bool {
+	return len(options.Keys) > 0 || options.KeyRegex != nil
 }
 
-func removeDuplicates(lines []string) []string {
-	if len(lines) <= 1 {
-		return lines
+// removeDuplicates keeps the first of every run of sorted records that compareUnique finds equal
+func removeDuplicates(records []record, comparators []keyComparator, options *p.KeySort) []record {
+	if len(records) <= 1 {
+		return records
 	}
 
-	result := make([]string, 0, len(lines))
-	result = append(result, lines[0])
+	result := make([]record, 0, len(records))
+	result = append(result, records[0])
 
-	for i := 1; i < len(lines); i++ {
-		if lines[i] != lines[i-1] {
-			result = append(result, lines[i])
+	for i := 1; i < len(records); i++ {
+		if compareUnique(records[i-1], records[i], comparators, options) != 0 {
+			result = append(result, records[i])
 		}
 	}
 
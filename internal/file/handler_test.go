@@ -1,9 +1,12 @@
 package file
 
 import (
+	"context"
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
 	"io"
 	"os"
-	"sort_utility/internal/args"
+	"strings"
 	"testing"
 )
 
@@ -11,6 +14,7 @@ func TestSortFile(t *testing.T) {
 	tests := []struct {
 		name            string
 		content         string
+		flags           []string
 		options         *args.KeySort
 		expectedLines   []string
 		expectedOutput  string
@@ -40,6 +44,24 @@ func TestSortFile(t *testing.T) {
 			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, Unique: true},
 			expectedLines: []string{"apple", "banana", "cherry"},
 		},
+		{
+			name:          "unique by key keeps the first line",
+			content:       "b 2\na 1\nc 01\nd 2.0\n",
+			flags:         []string{"-u", "-n", "-k", "2"},
+			expectedLines: []string{"a 1", "b 2"},
+		},
+		{
+			name:          "unique without keys compares whole lines",
+			content:       "a 2\na 1\nb 1\na 2\n",
+			flags:         []string{"-u"},
+			expectedLines: []string{"a 1", "a 2", "b 1"},
+		},
+		{
+			name:          "reverse unique by key keeps the first line",
+			content:       "b 2\na 1\nc 01\nd 2.0\n",
+			flags:         []string{"-u", "-r", "-n", "-k", "2"},
+			expectedLines: []string{"b 2", "a 1"},
+		},
 		{
 			name:          "month sort",
 			content:       "march\njanuary\nfebruary\n",
@@ -52,6 +74,12 @@ func TestSortFile(t *testing.T) {
 			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, HumanNumeric: true},
 			expectedLines: []string{"500", "2K", "1M"},
 		},
+		{
+			name:          "CRLF numeric sort with byte order mark",
+			content:       "\xEF\xBB\xBF10\r\n2\r\n1\r\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true},
+			expectedLines: []string{"1", "2", "10"},
+		},
 		{
 			name:          "column sort",
 			content:       "user1 30 admin\nuser2 25 user\nuser3 35 moderator\n",
@@ -61,17 +89,85 @@ func TestSortFile(t *testing.T) {
 		{
 			name:            "check sorted file",
 			content:         "apple\nbanana\ncherry\n",
-			options:         &args.KeySort{SortByColumn: true, ColumnNumber: 1, IsSorted: true},
+			options:         &args.KeySort{SortByColumn: true, ColumnNumber: 1, IsSorted: true, Language: "ru"},
 			expectedOutput:  "Файл отсортирован\n",
 			shouldReturnNil: true,
 		},
 		{
 			name:            "check unsorted file",
 			content:         "cherry\napple\nbanana\n",
-			options:         &args.KeySort{SortByColumn: true, ColumnNumber: 1, IsSorted: true},
+			options:         &args.KeySort{SortByColumn: true, ColumnNumber: 1, IsSorted: true, Language: "ru"},
 			expectedOutput:  "Файл не отсортирован\n",
 			shouldReturnNil: true,
 		},
+		{
+			name:          "header and footer stay in place",
+			content:       "NAME SIZE\nb 2\na 1\nc 3\nTOTAL 6\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, HeaderLines: 1, FooterLines: 1, Reverse: true},
+			expectedLines: []string{"NAME SIZE", "c 3", "b 2", "a 1", "TOTAL 6"},
+		},
+		{
+			name:          "header longer than file",
+			content:       "b\na\n",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, HeaderLines: 5, FooterLines: 1},
+			expectedLines: []string{"b", "a"},
+		},
+		{
+			name:            "check ignores header and footer",
+			content:         "zzz\napple\nbanana\naaa\n",
+			options:         &args.KeySort{SortByColumn: true, ColumnNumber: 1, IsSorted: true, Language: "ru", HeaderLines: 1, FooterLines: 1},
+			expectedOutput:  "Файл отсортирован\n",
+			shouldReturnNil: true,
+		},
+		{
+			name:          "zero terminated records with newlines",
+			content:       "b\nsecond line\x00c\x00a\x00",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, ZeroTerminated: true},
+			expectedLines: []string{"a", "b\nsecond line", "c"},
+		},
+		{
+			name:          "zero terminated unique without final terminator",
+			content:       "b\x00a\x00b",
+			options:       &args.KeySort{SortByColumn: true, ColumnNumber: 1, ZeroTerminated: true, Unique: true},
+			expectedLines: []string{"a", "b"},
+		},
+		{
+			name:            "zero terminated check",
+			content:         "a\nz\x00b\x00",
+			options:         &args.KeySort{SortByColumn: true, ColumnNumber: 1, ZeroTerminated: true, IsSorted: true, Language: "ru"},
+			expectedOutput:  "Файл отсортирован\n",
+			shouldReturnNil: true,
+		},
+		{
+			name:          "ip sort",
+			content:       "db 10.0.0.10\ngw 10.0.0.9\nv6 fe80::1\nbad n/a\nnet 10.0.0.0/24\n",
+			flags:         []string{"--ip", "-k", "2"},
+			expectedLines: []string{"net 10.0.0.0/24", "gw 10.0.0.9", "db 10.0.0.10", "v6 fe80::1", "bad n/a"},
+		},
+		{
+			name:          "semver sort",
+			content:       "1.0.0\n1.0.0-rc.1\nv0.9.0\n1.0.0-alpha.1\n1.0.0-alpha\n1.0.0-beta.11\n1.0.0-beta.2\n",
+			flags:         []string{"--semver", "--semver-allow-v"},
+			expectedLines: []string{"v0.9.0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0"},
+		},
+		{
+			name:          "natural sort",
+			content:       "img10.png\nimg2.png\nIMG1.png\nimg02.png\n",
+			flags:         []string{"--natural"},
+			expectedLines: []string{"IMG1.png", "img2.png", "img02.png", "img10.png"},
+		},
+		{
+			name:          "natural sort of multi-word lines",
+			content:       "chapter 11\nchapter 9\nchapter 2\n",
+			flags:         []string{"--natural"},
+			expectedLines: []string{"chapter 2", "chapter 9", "chapter 11"},
+		},
+		{
+			name:          "duration sort",
+			content:       "build 1h30m\nlint 250ms\ntest 2.5s\ndeploy 3d\ncache 00:05:00\n",
+			flags:         []string{"--duration", "-k", "2"},
+			expectedLines: []string{"lint 250ms", "test 2.5s", "cache 00:05:00", "build 1h30m", "deploy 3d"},
+		},
 		{
 			name:          "empty file",
 			content:       "",
@@ -102,6 +198,14 @@ func TestSortFile(t *testing.T) {
 			}
 			tmpFile.Close()
 
+			options := tt.options
+			if tt.flags != nil {
+				_, options, err = args.ParseArgs(append(tt.flags, tmpFile.Name()))
+				if err != nil {
+					t.Fatalf("ParseArgs() error = %v", err)
+				}
+			}
+
 			// Reopen for reading
 			file, err := os.Open(tmpFile.Name())
 			if err != nil {
@@ -110,12 +214,12 @@ func TestSortFile(t *testing.T) {
 			defer file.Close()
 
 			// Capture stdout for IsSorted tests
-			if tt.options.IsSorted {
+			if options.IsSorted {
 				oldStdout := os.Stdout
 				r, w, _ := os.Pipe()
 				os.Stdout = w
 
-				result, err := SortFile(file, tt.options)
+				result, err := SortFile(file, options)
 
 				w.Close()
 				os.Stdout = oldStdout
@@ -137,7 +241,7 @@ func TestSortFile(t *testing.T) {
 			}
 
 			// Regular sorting tests
-			result, err := SortFile(file, tt.options)
+			result, err := SortFile(file, options)
 			if err != nil {
 				t.Errorf("SortFile() error = %v", err)
 				return
@@ -182,6 +286,18 @@ func TestSortByColumn(t *testing.T) {
 			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Month: true},
 			expected: []string{"january", "february", "march"},
 		},
+		{
+			name:     "column range",
+			lines:    []string{"x b 2", "y a 9", "z b 1"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 2, Keys: []args.Key{{ColumnNumber: 2, EndColumn: 3}}},
+			expected: []string{"y a 9", "z b 1", "x b 2"},
+		},
+		{
+			name:     "reversed key",
+			lines:    []string{"a 1", "b 3", "a 2"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Keys: []args.Key{{ColumnNumber: 1}, {ColumnNumber: 2, Numeric: true, Reverse: true}}},
+			expected: []string{"a 2", "a 1", "b 3"},
+		},
 		{
 			name:     "human numeric sort",
 			lines:    []string{"2K", "1M", "500"},
@@ -212,6 +328,20 @@ func TestSortByColumn(t *testing.T) {
 			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 3},
 			expected: []string{"a", "bb ccc", "d"},
 		},
+		{
+			name:     "last column",
+			lines:    []string{"a b 30", "c 5", "d e f 100"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: -1, Numeric: true},
+			expected: []string{"c 5", "a b 30", "d e f 100"},
+		},
+		{
+			name:  "second column from the end",
+			lines: []string{"x 2 z", "1 y", "w v 3 u"},
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, Keys: []args.Key{
+				{ColumnNumber: -2, Numeric: true},
+			}},
+			expected: []string{"1 y", "x 2 z", "w v 3 u"},
+		},
 		{
 			name:     "empty lines",
 			lines:    []string{},
@@ -272,24 +402,34 @@ func TestWriteMsg(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Capture stdout
-			oldStdout := os.Stdout
-			r, w, _ := os.Pipe()
-			os.Stdout = w
-
-			writeMsg(tt.input)
-
-			w.Close()
-			os.Stdout = oldStdout
+			var output strings.Builder
+			if err := writeMsg(&output, tt.input); err != nil {
+				t.Fatalf("writeMsg() error = %v", err)
+			}
 
-			output, _ := io.ReadAll(r)
-			if string(output) != tt.expected {
-				t.Errorf("Expected output %q, got %q", tt.expected, string(output))
+			if output.String() != tt.expected {
+				t.Errorf("Expected output %q, got %q", tt.expected, output.String())
 			}
 		})
 	}
 }
 
+func TestSortCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := Sort(ctx, strings.NewReader("b\na\n"), &args.KeySort{SortByColumn: true, ColumnNumber: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Sort() expected context.Canceled, got %v", err)
+	}
+
+	records := lineRecords([]string{"c", "b", "a"})
+	err = sortRecordsContext(ctx, records, &args.KeySort{SortByColumn: true, ColumnNumber: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("sortRecordsContext() expected context.Canceled, got %v", err)
+	}
+}
+
 // Остальные существующие тесты остаются без изменений...
 func TestCompareNumeric(t *testing.T) {
 	tests := []struct {
@@ -306,7 +446,7 @@ func TestCompareNumeric(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := compareNumeric(tt.a, tt.b)
+			result := keyLess(t, "numeric", tt.a, tt.b)
 			if result != tt.expected {
 				t.Errorf("compareNumeric(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
 			}
@@ -330,7 +470,7 @@ func TestCompareMonth(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := compareMonth(tt.a, tt.b)
+			result := keyLess(t, "month", tt.a, tt.b)
 			if result != tt.expected {
 				t.Errorf("compareMonth(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
 			}
@@ -354,7 +494,7 @@ func TestCompareHumanNumeric(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := compareHumanNumeric(tt.a, tt.b)
+			result := keyLess(t, "human", tt.a, tt.b)
 			if result != tt.expected {
 				t.Errorf("compareHumanNumeric(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
 			}
@@ -367,21 +507,22 @@ func TestParseHumanNumeric(t *testing.T) {
 		name     string
 		input    string
 		expected float64
+		ok       bool
 	}{
-		{"plain number", "100", 100},
-		{"kilobyte", "1K", 1024},
-		{"megabyte", "1M", 1024 * 1024},
-		{"gigabyte", "1G", 1024 * 1024 * 1024},
-		{"decimal", "1.5K", 1.5 * 1024},
-		{"empty string", "", 0},
-		{"invalid", "abc", 0},
+		{"plain number", "100", 100, true},
+		{"kilobyte", "1K", 1024, true},
+		{"megabyte", "1M", 1024 * 1024, true},
+		{"gigabyte", "1G", 1024 * 1024 * 1024, true},
+		{"decimal", "1.5K", 1.5 * 1024, true},
+		{"empty string", "", 0, false},
+		{"invalid", "abc", 0, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseHumanNumeric(tt.input)
-			if result != tt.expected {
-				t.Errorf("parseHumanNumeric(%q) = %f, want %f", tt.input, result, tt.expected)
+			result, ok := parseHumanNumeric(tt.input)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("parseHumanNumeric(%q) = %f, %v, want %f, %v", tt.input, result, ok, tt.expected, tt.ok)
 			}
 		})
 	}
@@ -391,6 +532,7 @@ func TestRemoveDuplicates(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    []string
+		options  *args.KeySort
 		expected []string
 	}{
 		{
@@ -413,11 +555,36 @@ func TestRemoveDuplicates(t *testing.T) {
 			input:    []string{"a"},
 			expected: []string{"a"},
 		},
+		{
+			name:     "equal numeric keys",
+			input:    []string{"1 apple", "1.0 banana", "2 cherry"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true, Keys: []args.Key{{ColumnNumber: 1}}},
+			expected: []string{"1 apple", "2 cherry"},
+		},
+		{
+			name:     "equal keys of different columns",
+			input:    []string{"a 1", "b 1", "c 2"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 2, Keys: []args.Key{{ColumnNumber: 2}}},
+			expected: []string{"a 1", "c 2"},
+		},
+		{
+			name:     "equal first fields without keys",
+			input:    []string{"a 1", "a 2", "b 1"},
+			expected: []string{"a 1", "a 2", "b 1"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := removeDuplicates(tt.input)
+			options := tt.options
+			if options == nil {
+				options = &args.KeySort{SortByColumn: true, ColumnNumber: 1}
+			}
+			comparators := newComparators(options)
+			records := lineRecords(tt.input)
+			parseKeys(records, comparators, options)
+
+			result := recordLines(removeDuplicates(records, comparators, options))
 			if len(result) != len(tt.expected) {
 				t.Errorf("expected length %d, got %d", len(tt.expected), len(result))
 				return
@@ -589,3 +756,27 @@ func TestOpenFile(t *testing.T) {
 		})
 	}
 }
+
+// openTestFile writes content to a temporary file and opens it for reading
+func openTestFile(t *testing.T, content string) *os.File {
+	t.Helper()
+
+	tmpFile, err := os.CreateTemp("", "test_*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	_, err = tmpFile.WriteString(content)
+	if err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	file, err := os.Open(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to open temp file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
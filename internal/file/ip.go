@@ -0,0 +1,43 @@
+package file
+
+import (
+	"cmp"
+	"net/netip"
+	"strings"
+)
+
+// ipValue is an IP address or CIDR prefix prepared for comparison
+type ipValue struct {
+	network netip.Addr // Network address, equal to addr for a plain address
+	bits    int        // Prefix length, the full address length for a plain address
+	addr    netip.Addr // Address as written
+}
+
+// parseIP parses an IPv4 or IPv6 address, optionally with a /prefix length
+func parseIP(s string) (ipValue, bool) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return ipValue{}, false
+		}
+		return ipValue{network: prefix.Masked().Addr(), bits: prefix.Bits(), addr: prefix.Addr()}, true
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return ipValue{}, false
+	}
+	return ipValue{network: addr, bits: addr.BitLen(), addr: addr}, true
+}
+
+// compareIP orders IPv4 before IPv6, then by network address and prefix length
+func compareIP(a, b ipValue) int {
+	// netip.Addr.Compare already puts IPv4 before IPv6
+	if c := a.network.Compare(b.network); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.bits, b.bits); c != 0 {
+		return c
+	}
+	return a.addr.Compare(b.addr)
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"testing"
+)
+
+func TestCompareIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"numeric octets", "10.0.0.9", "10.0.0.10", true},
+		{"reverse octets", "10.0.0.10", "10.0.0.9", false},
+		{"ipv4 before ipv6", "255.255.255.255", "::1", true},
+		{"ipv6 after ipv4", "::1", "1.1.1.1", false},
+		{"ipv6 numeric", "2001:db8::9", "2001:db8::10", true},
+		{"network before address", "10.0.0.0/8", "10.0.0.1", true},
+		{"shorter prefix first", "10.0.0.0/8", "10.0.0.0/16", true},
+		{"prefix by network", "10.0.1.0/24", "10.0.0.0/16", false},
+		{"invalid last", "host.local", "10.0.0.1", false},
+		{"valid before invalid", "10.0.0.1", "host.local", true},
+		{"both invalid", "a", "b", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := keyLess(t, "ip", tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("compareIP(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// jsonArray keeps the whitespace around the elements of a JSON array document
+type jsonArray struct {
+	open      string // Whitespace after "["
+	separator string // Whitespace after each ","
+	close     string // Whitespace before "]"
+}
+
+// join writes the elements back into an array with the original layout
+func (a jsonArray) join(elements []string) string {
+	if len(elements) == 0 {
+		return "[]"
+	}
+	return "[" + a.open + strings.Join(elements, ","+a.separator) + a.close + "]"
+}
+
+// readJSONArray reads a document holding a single JSON array and makes a record of every element
+// The element text is kept byte for byte, so nested formatting survives the sort
+func readJSONArray(in *input, options *p.KeySort) (jsonArray, []record, *p.KeySort, error) {
+	data, err := io.ReadAll(in.reader)
+	if err != nil {
+		return jsonArray{}, nil, nil, err
+	}
+	in.format.FinalNewline = len(data) == 0 || data[len(data)-1] == '\n'
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return jsonArray{}, nil, nil, messages.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return jsonArray{}, nil, nil, messages.Errorf("%w: expected an array", ErrInvalidJSON)
+	}
+
+	resolved, paths := resolvePaths(options)
+
+	var layout jsonArray
+	var records []record
+	end := int(decoder.InputOffset())
+	for decoder.More() {
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			return jsonArray{}, nil, nil, messages.Errorf("%w: element %d: %v", ErrInvalidJSON, len(records)+1, err)
+		}
+
+		// The gap before an element is whitespace, preceded by a comma after the first element
+		start := int(decoder.InputOffset()) - len(element)
+		gap := string(data[end:start])
+		if len(records) == 0 {
+			layout.open = gap
+		} else if len(records) == 1 {
+			_, layout.separator, _ = strings.Cut(gap, ",")
+		}
+		end = int(decoder.InputOffset())
+
+		value, err := decodeJSON(element)
+		if err != nil {
+			return jsonArray{}, nil, nil, messages.Errorf("%w: element %d: %v", ErrInvalidJSON, len(records)+1, err)
+		}
+		records = append(records, jsonRecord(string(element), value, paths))
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return jsonArray{}, nil, nil, messages.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	layout.close = string(data[end : decoder.InputOffset()-1])
+	if _, err := decoder.Token(); err != io.EOF {
+		return jsonArray{}, nil, nil, messages.Errorf("%w: unexpected data after the array", ErrInvalidJSON)
+	}
+
+	return layout, records, resolved, nil
+}
@@ -0,0 +1,85 @@
+package file
+
+import (
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"testing"
+)
+
+func TestSortFileJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		options  *args.KeySort
+		expected string
+	}{
+		{
+			name:    "indented elements keep formatting",
+			content: "[\n  {\"name\": \"b\", \"prio\": 2},\n  {\n    \"name\": \"a\",\n    \"prio\": 1\n  },\n  {\"name\": \"c\", \"prio\": 10}\n]\n",
+			options: &args.KeySort{SortByColumn: true, JSON: true, Keys: []args.Key{{Path: ".prio"}}},
+			expected: "[\n  {\n    \"name\": \"a\",\n    \"prio\": 1\n  },\n" +
+				"  {\"name\": \"b\", \"prio\": 2},\n  {\"name\": \"c\", \"prio\": 10}\n]",
+		},
+		{
+			name:    "compact document with two keys and reverse",
+			content: `[{"g":"x","n":1},{"g":"y","n":5},{"g":"x","n":3}]`,
+			options: &args.KeySort{SortByColumn: true, JSON: true, Reverse: true, Keys: []args.Key{
+				{Path: ".g"}, {Path: ".n"},
+			}},
+			expected: `[{"g":"y","n":5},{"g":"x","n":3},{"g":"x","n":1}]`,
+		},
+		{
+			name:     "whole elements without keys",
+			content:  "[3, 1, 2]",
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, JSON: true},
+			expected: "[1, 2, 3]",
+		},
+		{
+			name:     "unique elements",
+			content:  `["b","a","b"]`,
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, JSON: true, Unique: true},
+			expected: `["a","b"]`,
+		},
+		{
+			name:     "empty array",
+			content:  "[ ]",
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, JSON: true},
+			expected: "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SortFile(openTestFile(t, tt.content), tt.options)
+			if err != nil {
+				t.Fatalf("SortFile() error = %v", err)
+			}
+
+			if len(result) != 1 || result[0] != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSortFileJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"object document", `{"a": 1}`},
+		{"broken element", `[1, {"a": ]`},
+		{"trailing data", `[1, 2] [3]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &args.KeySort{SortByColumn: true, ColumnNumber: 1, JSON: true}
+
+			_, err := SortFile(openTestFile(t, tt.content), options)
+			if !errors.Is(err, ErrInvalidJSON) {
+				t.Errorf("expected ErrInvalidJSON, got %v", err)
+			}
+		})
+	}
+}
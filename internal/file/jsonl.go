@@ -0,0 +1,134 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// ErrInvalidJSON is returned when a JSON Lines record cannot be decoded
+var ErrInvalidJSON = messages.New("invalid JSON")
+
+// jsonlRecords decodes every line and extracts the key paths; the lines are kept unchanged
+// Error line numbers count the offset lines before the first one
+func jsonlRecords(lines []string, offset int, options *p.KeySort) ([]record, *p.KeySort, error) {
+	resolved, paths := resolvePaths(options)
+
+	records := make([]record, len(lines))
+	for n, line := range lines {
+		value, err := decodeJSON([]byte(line))
+		if err != nil {
+			return nil, nil, messages.Errorf("%w: line %d: %v", ErrInvalidJSON, offset+n+1, err)
+		}
+		records[n] = jsonRecord(line, value, paths)
+	}
+	return records, resolved, nil
+}
+
+// resolvePaths returns a copy of options with keys renumbered to the position of their path
+// in the record fields, and the split paths. Without keys the whole value is compared
+func resolvePaths(options *p.KeySort) (*p.KeySort, [][]string) {
+	resolved := *options
+	resolved.Keys = make([]p.Key, len(options.Keys))
+	copy(resolved.Keys, options.Keys)
+	if len(resolved.Keys) == 0 {
+		resolved.Keys = []p.Key{{Path: "."}}
+	}
+
+	paths := make([][]string, len(resolved.Keys))
+	for i := range resolved.Keys {
+		resolved.Keys[i].ColumnNumber = i + 1
+		paths[i] = splitJSONPath(resolved.Keys[i].Path)
+	}
+	return &resolved, paths
+}
+
+// jsonRecord builds a record holding the values found at paths
+func jsonRecord(line string, value any, paths [][]string) record {
+	rec := record{line: line, fields: make([]string, len(paths)), kinds: make([]valueKind, len(paths))}
+	for i, path := range paths {
+		rec.fields[i], rec.kinds[i] = jsonField(lookupJSON(value, path))
+	}
+	return rec
+}
+
+// decodeJSON decodes a single JSON value keeping numbers as json.Number
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, messages.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// splitJSONPath splits a path such as .request.items[0].id into its members
+// The path "." selects the whole value
+func splitJSONPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	var members []string
+	for _, member := range strings.Split(path, ".") {
+		if member != "" {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// lookupJSON follows the path members through objects and arrays
+func lookupJSON(value any, path []string) (any, bool) {
+	for _, member := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[member]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(member)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// jsonField returns the text used for comparison and the kind of a JSON value
+func jsonField(value any, found bool) (string, valueKind) {
+	if !found {
+		return "", kindMissing
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", kindMissing
+	case bool:
+		return strconv.FormatBool(v), kindBool
+	case json.Number:
+		return v.String(), kindNumber
+	case string:
+		return v, kindString
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", kindMissing
+		}
+		return string(data), kindComposite
+	}
+}
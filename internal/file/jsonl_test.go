@@ -0,0 +1,145 @@
+package file
+
+import (
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{".", nil},
+		{".user.name", []string{"user", "name"}},
+		{".items[0].id", []string{"items", "0", "id"}},
+		{".items.1", []string{"items", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := splitJSONPath(tt.path)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("splitJSONPath(%q) = %q, want %q", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJSONField(t *testing.T) {
+	value, err := decodeJSON([]byte(`{"a":{"b":[10,"x",null,true,{"c":1}]}}`))
+	if err != nil {
+		t.Fatalf("decodeJSON() error = %v", err)
+	}
+
+	tests := []struct {
+		path         string
+		expectedText string
+		expectedKind valueKind
+	}{
+		{".a.b[0]", "10", kindNumber},
+		{".a.b[1]", "x", kindString},
+		{".a.b[2]", "", kindMissing},
+		{".a.b[3]", "true", kindBool},
+		{".a.b[4]", `{"c":1}`, kindComposite},
+		{".a.b[9]", "", kindMissing},
+		{".a.missing", "", kindMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			text, kind := jsonField(lookupJSON(value, splitJSONPath(tt.path)))
+			if text != tt.expectedText || kind != tt.expectedKind {
+				t.Errorf("jsonField(%s) = %q, %v, want %q, %v", tt.path, text, kind, tt.expectedText, tt.expectedKind)
+			}
+		})
+	}
+}
+
+func TestSortFileJSONL(t *testing.T) {
+	lines := []string{
+		`{"user":{"name":"bob"},"request":{"latency_ms":120}}`,
+		`{"user":{"name":"Alice"},"request":{"latency_ms":9.5}}`,
+		`{"user":{"name":"carol"},"request":{}}`,
+		`{"user":{"name":"dave"},"request":{"latency_ms":1000}}`,
+	}
+	content := strings.Join(lines, "\n") + "\n"
+
+	tests := []struct {
+		name          string
+		options       *args.KeySort
+		expectedLines []int
+	}{
+		{
+			name: "numbers numerically, missing last",
+			options: &args.KeySort{SortByColumn: true, JSONL: true, Keys: []args.Key{
+				{Path: ".request.latency_ms"},
+			}},
+			expectedLines: []int{1, 0, 3, 2},
+		},
+		{
+			name: "missing first",
+			options: &args.KeySort{SortByColumn: true, JSONL: true, JSONMissing: args.InvalidFirst, Keys: []args.Key{
+				{Path: ".request.latency_ms"},
+			}},
+			expectedLines: []int{2, 1, 0, 3},
+		},
+		{
+			name: "strings with key type",
+			options: &args.KeySort{SortByColumn: true, JSONL: true, Keys: []args.Key{
+				{Path: ".user.name", Natural: true},
+			}},
+			expectedLines: []int{1, 0, 2, 3},
+		},
+		{
+			name: "strings lexically",
+			options: &args.KeySort{SortByColumn: true, JSONL: true, Keys: []args.Key{
+				{Path: ".user.name"},
+			}},
+			expectedLines: []int{1, 0, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SortFile(openTestFile(t, content), tt.options)
+			if err != nil {
+				t.Fatalf("SortFile() error = %v", err)
+			}
+
+			if len(result) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d lines, got %d", len(tt.expectedLines), len(result))
+			}
+			for i, line := range result {
+				if line != lines[tt.expectedLines[i]] {
+					t.Errorf("At index %d: expected %q, got %q", i, lines[tt.expectedLines[i]], line)
+				}
+			}
+		})
+	}
+}
+
+func TestSortFileJSONLInvalid(t *testing.T) {
+	options := &args.KeySort{SortByColumn: true, JSONL: true, Keys: []args.Key{{Path: ".a"}}}
+
+	_, err := SortFile(openTestFile(t, "{\"a\":1}\nnot json\n"), options)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestSortFileJSONLHeader(t *testing.T) {
+	options := &args.KeySort{SortByColumn: true, JSONL: true, HeaderLines: 1, Keys: []args.Key{{Path: ".a"}}}
+	expected := []string{"# exported records", `{"a":1}`, `{"a":2}`}
+
+	result, err := SortFile(openTestFile(t, "# exported records\n{\"a\":2}\n{\"a\":1}\n"), options)
+	if err != nil {
+		t.Fatalf("SortFile() error = %v", err)
+	}
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
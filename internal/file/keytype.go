@@ -0,0 +1,163 @@
+package file
+
+import (
+	"cmp"
+	"strings"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// errInvalidKey is the Parse error of built-in key types for text that is not a value of the type
+var errInvalidKey = messages.New("invalid key")
+
+// Built-in key types without an argument
+var (
+	lexicalKeyType  = valueKeyType[string]{parse: parseText, compare: strings.Compare}
+	numericKeyType  = valueKeyType[float64]{parse: parseNumber, compare: cmp.Compare[float64]}
+	monthKeyType    = valueKeyType[int]{parse: parseMonth, compare: cmp.Compare[int]}
+	humanKeyType    = valueKeyType[float64]{parse: parseHumanNumeric, compare: cmp.Compare[float64]}
+	ipKeyType       = valueKeyType[ipValue]{parse: parseIP, compare: compareIP}
+	durationKeyType = valueKeyType[float64]{parse: parseDuration, compare: cmp.Compare[float64]}
+	naturalKeyType  = spanningKeyType{valueKeyType[string]{parse: parseText, compare: naturalCompare}}
+)
+
+// builtinKeyTypes are the key types registered by this package
+var builtinKeyTypes = map[string]p.KeyTypeFactory{
+	"lexical":  fixedFactory(lexicalKeyType),
+	"numeric":  fixedFactory(numericKeyType),
+	"month":    fixedFactory(monthKeyType),
+	"human":    fixedFactory(humanKeyType),
+	"ip":       fixedFactory(ipKeyType),
+	"duration": fixedFactory(durationKeyType),
+	"natural":  fixedFactory(naturalKeyType),
+	"date": func(arg string, options *p.KeySort) (p.KeyType, error) {
+		return newDateKeyType(arg, options), nil
+	},
+	"semver": func(arg string, options *p.KeySort) (p.KeyType, error) {
+		allowV := arg == "v" || options.SemVerV
+		parse := func(key string) (semVer, bool) {
+			return parseSemVer(key, allowV)
+		}
+		return valueKeyType[semVer]{parse: parse, compare: compareSemVer}, nil
+	},
+}
+
+func init() {
+	for name, factory := range builtinKeyTypes {
+		if err := p.RegisterKeyType(name, factory); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// valueKeyType is a built-in key type whose keys parse into values of T, ordered by compare
+// Keys that parse reports as not ok are invalid, compareKeys places them
+type valueKeyType[T any] struct {
+	parse   func(key string) (T, bool)
+	compare func(a, b T) int
+}
+
+func (t valueKeyType[T]) Parse(key string) (any, error) {
+	value, ok := t.parse(key)
+	if !ok {
+		return nil, errInvalidKey
+	}
+	return value, nil
+}
+
+func (t valueKeyType[T]) Compare(a, b any) int {
+	return t.compare(a.(T), b.(T))
+}
+
+// fixedFactory makes the factory of a built-in key type without an argument
+func fixedFactory(keyType p.KeyType) p.KeyTypeFactory {
+	return func(string, *p.KeySort) (p.KeyType, error) {
+		return keyType, nil
+	}
+}
+
+// parseText is the parse function of key types ordering the text itself
+func parseText(key string) (string, bool) {
+	return key, true
+}
+
+// spanningKeyType is a built-in key type whose values may contain spaces, such as natural text
+type spanningKeyType struct {
+	p.KeyType
+}
+
+func (spanningKeyType) spansFields() bool {
+	return true
+}
+
+// invalidPlacer is implemented by key types that may put invalid values before the valid ones
+type invalidPlacer interface {
+	invalidFirst() bool
+}
+
+// fieldSpanner is implemented by key types whose values run from the key column to the end of the line
+// when the line is split on whitespace
+type fieldSpanner interface {
+	spansFields() bool
+}
+
+// newKeyType makes the registered key type of key, lexical if the factory fails
+// The factories of registered types are checked when the options are validated
+func newKeyType(key p.Key, options *p.KeySort) p.KeyType {
+	name, arg := key.KeyTypeName()
+	if factory, ok := p.LookupKeyType(name); ok {
+		if keyType, err := factory(arg, options); err == nil {
+			return keyType
+		}
+	}
+	return lexicalKeyType
+}
+
+// KeyOrder returns how the texts of key are parsed and how the parsed values compare,
+// the ordering sorting by key uses. Parse each text once and compare the results
+func KeyOrder(key p.Key, options *p.KeySort) (func(text string) any, func(a, b any) int) {
+	keyType := newKeyType(key, options)
+	parse := func(text string) any {
+		return parseKey(keyType, text)
+	}
+	compare := func(a, b any) int {
+		return compareKeys(keyType, a.(keyValue), b.(keyValue))
+	}
+	return parse, compare
+}
+
+// parseKey parses the text of a key with keyType
+func parseKey(keyType p.KeyType, text string) keyValue {
+	value, err := keyType.Parse(text)
+	return keyValue{text: text, value: value, valid: err == nil, found: true}
+}
+
+// compareKeys compares two parsed keys of keyType and returns -1, 0 or +1
+// Values that failed to parse go after valid ones, or before them when keyType is an
+// invalidPlacer that says so, and are compared as text among themselves
+func compareKeys(keyType p.KeyType, a, b keyValue) int {
+	switch {
+	case a.valid && b.valid:
+		return keyType.Compare(a.value, b.value)
+	case !a.valid && !b.valid:
+		return strings.Compare(a.text, b.text)
+	}
+
+	c := 1
+	if a.valid {
+		c = -1
+	}
+	if placer, ok := keyType.(invalidPlacer); ok && placer.invalidFirst() {
+		c = -c
+	}
+	return c
+}
+
+// keyTypeValid returns whether a key is a valid value of keyType
+func keyTypeValid(keyType p.KeyType) func(key string) bool {
+	return func(key string) bool {
+		_, err := keyType.Parse(key)
+		return err == nil
+	}
+}
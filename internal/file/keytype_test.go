@@ -0,0 +1,105 @@
+package file
+
+import (
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// hexType parses keys as hexadecimal numbers
+type hexType struct{}
+
+func (hexType) Parse(key string) (any, error) {
+	return strconv.ParseInt(key, 16, 64)
+}
+
+func (hexType) Compare(a, b any) int {
+	switch {
+	case a.(int64) < b.(int64):
+		return -1
+	case a.(int64) > b.(int64):
+		return 1
+	}
+	return 0
+}
+
+// keyLess reports whether key a sorts before key b with the built-in key type name
+func keyLess(t *testing.T, name, a, b string) bool {
+	t.Helper()
+	keyType, err := builtinKeyTypes[name]("", &args.KeySort{})
+	if err != nil {
+		t.Fatalf("factory of %q error = %v", name, err)
+	}
+	return compareKeys(keyType, parseKey(keyType, a), parseKey(keyType, b)) < 0
+}
+
+func TestCompareKeys(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"a", "10", -1},
+		{"10", "a", 1},
+		{"ff", "zz", -1},
+		{"zz", "ff", 1},
+		{"xx", "zz", -1},
+		{"f", "f", 0},
+	}
+
+	for _, tt := range tests {
+		a, b := parseKey(hexType{}, tt.a), parseKey(hexType{}, tt.b)
+		if result := compareKeys(hexType{}, a, b); result != tt.expected {
+			t.Errorf("compareKeys(%q, %q) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestBuiltinKeyTypes(t *testing.T) {
+	for name := range builtinKeyTypes {
+		factory, ok := args.LookupKeyType(name)
+		if !ok {
+			t.Errorf("built-in key type %q is not registered", name)
+			continue
+		}
+		if _, err := factory("", &args.KeySort{}); err != nil {
+			t.Errorf("factory of %q error = %v", name, err)
+		}
+	}
+
+	if err := args.RegisterKeyType("date", nil); !errors.Is(err, args.ErrDuplicateKeyType) {
+		t.Errorf("expected ErrDuplicateKeyType, got %v", err)
+	}
+}
+
+func TestBuiltinKeyTypeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		expected any
+	}{
+		{"numeric", "2.5", 2.5},
+		{"month", "Mar", 3},
+		{"human", "2K", 2048.0},
+		{"duration", "1s", float64(time.Second)},
+		{"ip", "10.0.0.1", ipValue{network: netip.MustParseAddr("10.0.0.1"), bits: 32, addr: netip.MustParseAddr("10.0.0.1")}},
+		{"semver", "1.2.3", semVer{core: [3]string{"1", "2", "3"}}},
+		{"lexical", "text", "text"},
+	}
+
+	for _, tt := range tests {
+		keyType, _ := builtinKeyTypes[tt.name]("", &args.KeySort{})
+		value, err := keyType.Parse(tt.key)
+		if err != nil || !reflect.DeepEqual(value, tt.expected) {
+			t.Errorf("%s Parse(%q) = %#v, %v, expected %#v", tt.name, tt.key, value, err, tt.expected)
+		}
+		if tt.name != "lexical" {
+			if _, err := keyType.Parse("garbage"); err == nil {
+				t.Errorf("%s Parse(\"garbage\") expected an error", tt.name)
+			}
+		}
+	}
+}
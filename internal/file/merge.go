@@ -0,0 +1,164 @@
+package file
+
+import (
+	"container/heap"
+	"context"
+	"io"
+	"iter"
+	"strings"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// ErrMergeFormat is returned when merging input that is not line oriented
+var ErrMergeFormat = messages.New("cannot merge --csv, --tsv or --json input or keep --header and --footer lines")
+
+// Merge yields the lines of several inputs that are each already sorted, in sorted order
+// Only the current line of every input is held in memory. Lines that compare equal come
+// from the earlier input first; with -r the inputs must be sorted backwards and with -u
+// only the first of the lines compareUnique finds equal is yielded
+func Merge(ctx context.Context, readers []io.Reader, options *p.KeySort) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if options.CSV || options.TSV || options.JSON || options.HeaderLines > 0 || options.FooterLines > 0 {
+			yield("", ErrMergeFormat)
+			return
+		}
+
+		newRecord, resolved := lineRecorder(options)
+		queue := &mergeQueue{comparators: newComparators(resolved), options: resolved, compare: compareRecords}
+		if options.Unique {
+			queue.compare = compareUnique
+		}
+
+		// next reads the following record of a source and queues the source unless it is exhausted
+		next := func(source *mergeSource) error {
+			line, ok, err := source.in.next(options)
+			if err != nil || !ok {
+				return err
+			}
+
+			rec, err := newRecord(line)
+			if err == nil {
+				rec.keys = recordKeys(rec, queue.comparators, resolved)
+				if value, bad := invalidDate(rec, queue.comparators); bad && resolved.DateInvalid == p.InvalidError {
+					err = messages.Errorf("%w: %s", ErrInvalidDate, value)
+				}
+			}
+			if err != nil {
+				return messages.Errorf("%s:%d: %w", source.in.name, source.in.lines, err)
+			}
+
+			source.record = rec
+			heap.Push(queue, source)
+			return nil
+		}
+
+		for i, r := range readers {
+			in, err := newInput(contextReader{ctx: ctx, reader: r})
+			if err == nil {
+				err = next(&mergeSource{in: in, index: i})
+			}
+			if err != nil {
+				yield("", err)
+				return
+			}
+		}
+
+		var previous record
+		for count := 0; queue.Len() > 0; count++ {
+			source := heap.Pop(queue).(*mergeSource)
+			rec := source.record
+
+			if !options.Unique || count == 0 || queue.compare(previous, rec, queue.comparators, resolved) != 0 {
+				if !yield(rec.line, nil) {
+					return
+				}
+			}
+			previous = rec
+
+			if err := next(source); err != nil {
+				yield("", err)
+				return
+			}
+		}
+	}
+}
+
+// lineRecorder returns how one line becomes a record in the line-oriented input formats,
+// and the options with the keys resolved for those records
+func lineRecorder(options *p.KeySort) (func(line string) (record, error), *p.KeySort) {
+	switch {
+	case options.JSONL:
+		resolved, paths := resolvePaths(options)
+		return func(line string) (record, error) {
+			value, err := decodeJSON([]byte(line))
+			if err != nil {
+				return record{}, messages.Errorf("%w: %v", ErrInvalidJSON, err)
+			}
+			return jsonRecord(line, value, paths), nil
+		}, resolved
+	case options.KeyRegex != nil:
+		return func(line string) (record, error) {
+			rec, ok := regexRecord(line, options)
+			if !ok && options.KeyRegexNoMatch == p.InvalidError {
+				return record{}, messages.Errorf("%w: %s", ErrNoMatch, line)
+			}
+			return rec, nil
+		}, options
+	case fixedColumns(options):
+		_, resolved := columnRecords(nil, options)
+		return func(line string) (record, error) {
+			records, _ := columnRecords([]string{line}, options)
+			return records[0], nil
+		}, resolved
+	}
+
+	return func(line string) (record, error) {
+		return record{line: line, fields: strings.Fields(line)}, nil
+	}, options
+}
+
+// mergeSource is one input of a merge with its current record
+type mergeSource struct {
+	in     *input
+	index  int // Position of the input, earlier inputs win ties
+	record record
+}
+
+// mergeQueue is a heap of the merge sources ordered by their current record
+type mergeQueue struct {
+	sources     []*mergeSource
+	comparators []keyComparator
+	options     *p.KeySort
+	compare     func(recordI, recordJ record, comparators []keyComparator, options *p.KeySort) int // Orders the records, compareUnique with -u
+}
+
+func (q *mergeQueue) Len() int {
+	return len(q.sources)
+}
+
+func (q *mergeQueue) Less(i, j int) bool {
+	c := q.compare(q.sources[i].record, q.sources[j].record, q.comparators, q.options)
+	if q.options.Reverse {
+		c = -c
+	}
+	if c != 0 {
+		return c < 0
+	}
+	return q.sources[i].index < q.sources[j].index
+}
+
+func (q *mergeQueue) Swap(i, j int) {
+	q.sources[i], q.sources[j] = q.sources[j], q.sources[i]
+}
+
+func (q *mergeQueue) Push(x any) {
+	q.sources = append(q.sources, x.(*mergeSource))
+}
+
+func (q *mergeQueue) Pop() any {
+	last := q.sources[len(q.sources)-1]
+	q.sources = q.sources[:len(q.sources)-1]
+	return last
+}
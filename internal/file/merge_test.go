@@ -0,0 +1,155 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   []string
+		options  *args.KeySort
+		expected []string
+	}{
+		{
+			name:     "lexical",
+			inputs:   []string{"a\nc\ne\n", "b\nd\n", ""},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1},
+			expected: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "numeric column",
+			inputs:   []string{"x 2\ny 10\n", "z 1\nw 3"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true},
+			expected: []string{"z 1", "x 2", "w 3", "y 10"},
+		},
+		{
+			name:     "earlier input wins ties",
+			inputs:   []string{"a 1\n", "b 1\n"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true},
+			expected: []string{"a 1", "b 1"},
+		},
+		{
+			name:     "reverse",
+			inputs:   []string{"c\na\n", "d\nb\n"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Reverse: true},
+			expected: []string{"d", "c", "b", "a"},
+		},
+		{
+			name:     "unique",
+			inputs:   []string{"a\nb\nb\n", "b\nc\n"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Unique: true},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "unique by key",
+			inputs:   []string{"x 1\ny 2\n", "z 1.0\nw 3\n"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 2, Numeric: true, Unique: true, Keys: []args.Key{{ColumnNumber: 2}}},
+			expected: []string{"x 1", "y 2", "w 3"},
+		},
+		{
+			name:     "unique without keys compares lines",
+			inputs:   []string{"a 1\na 2\n", "a 1\nb 1\n"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, Unique: true},
+			expected: []string{"a 1", "a 2", "b 1"},
+		},
+		{
+			name:     "json lines",
+			inputs:   []string{"{\"n\":1}\n{\"n\":10}\n", "{\"n\":2}\n"},
+			options:  &args.KeySort{JSONL: true, Keys: []args.Key{{Path: ".n"}}},
+			expected: []string{"{\"n\":1}", "{\"n\":2}", "{\"n\":10}"},
+		},
+		{
+			name:     "zero terminated",
+			inputs:   []string{"a\nx\x00c\x00", "b\x00"},
+			options:  &args.KeySort{SortByColumn: true, ColumnNumber: 1, ZeroTerminated: true},
+			expected: []string{"a\nx", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := make([]io.Reader, len(tt.inputs))
+			for i, input := range tt.inputs {
+				readers[i] = strings.NewReader(input)
+			}
+
+			var result []string
+			for line, err := range Merge(context.Background(), readers, tt.options) {
+				if err != nil {
+					t.Fatalf("Merge() error = %v", err)
+				}
+				result = append(result, line)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	file := openTestFile(t, "a-1\nb\n")
+
+	options := &args.KeySort{KeyRegex: regexp.MustCompile(`-(\d+)`), KeyRegexNoMatch: args.InvalidError}
+	var err error
+	for _, err = range Merge(context.Background(), []io.Reader{file}, options) {
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("expected ErrNoMatch, got %v", err)
+	}
+	if expected := file.Name() + ":2: "; !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("expected error to start with %q, got %q", expected, err.Error())
+	}
+
+	for _, err = range Merge(context.Background(), nil, &args.KeySort{CSV: true}) {
+	}
+	if !errors.Is(err, ErrMergeFormat) {
+		t.Errorf("expected ErrMergeFormat, got %v", err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options *args.KeySort
+	}{
+		{"basic", "c\na\nb\n", &args.KeySort{SortByColumn: true, ColumnNumber: 1}},
+		{"reverse unique", "b\na\nb\nc\na\n", &args.KeySort{SortByColumn: true, ColumnNumber: 1, Reverse: true, Unique: true}},
+		{"header and footer", "name\nb\na\ntotal\n", &args.KeySort{SortByColumn: true, ColumnNumber: 1, HeaderLines: 1, FooterLines: 1}},
+		{"json array", "[3, 1, 2]", &args.KeySort{JSON: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, _, err := Sort(context.Background(), strings.NewReader(tt.content), tt.options)
+			if err != nil {
+				t.Fatalf("Sort() error = %v", err)
+			}
+
+			var result []string
+			for line, err := range All(context.Background(), strings.NewReader(tt.content), tt.options) {
+				if err != nil {
+					t.Fatalf("All() error = %v", err)
+				}
+				result = append(result, line)
+			}
+
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("Expected %q, got %q", expected, result)
+			}
+		})
+	}
+}
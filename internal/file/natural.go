@@ -0,0 +1,89 @@
+package file
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isDigit reports whether b is an ASCII digit
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// naturalCompare splits a and b into text and digit runs and returns -1, 0 or +1
+// Digit runs are compared by value, text runs case-insensitively. Ties are broken
+// first by the number of leading zeros (fewer first) and then by plain byte order
+func naturalCompare(a, b string) int {
+	zeroTie := 0
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+
+			runA, runB := a[startA:i], b[startB:j]
+			trimmedA := strings.TrimLeft(runA, "0")
+			trimmedB := strings.TrimLeft(runB, "0")
+			if c := compareDigits(trimmedA, trimmedB); c != 0 {
+				return c
+			}
+			if zeroTie == 0 && len(runA) != len(runB) {
+				if len(runA) < len(runB) {
+					zeroTie = -1
+				} else {
+					zeroTie = 1
+				}
+			}
+			continue
+		}
+
+		startA, startB := i, j
+		for i < len(a) && !isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && !isDigit(b[j]) {
+			j++
+		}
+		if c := compareFold(a[startA:i], b[startB:j]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i < len(a):
+		return 1
+	case j < len(b):
+		return -1
+	case zeroTie != 0:
+		return zeroTie
+	}
+	return strings.Compare(a, b)
+}
+
+// compareFold compares two strings rune by rune ignoring case
+func compareFold(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	for k := 0; k < len(runesA) && k < len(runesB); k++ {
+		lowerA, lowerB := unicode.ToLower(runesA[k]), unicode.ToLower(runesB[k])
+		if lowerA != lowerB {
+			if lowerA < lowerB {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(runesA) < len(runesB):
+		return -1
+	case len(runesA) > len(runesB):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"testing"
+)
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"embedded numbers", "img2.png", "img10.png", true},
+		{"reverse embedded numbers", "img10.png", "img2.png", false},
+		{"case-insensitive text", "Chapter 9", "chapter 11", true},
+		{"text before longer text", "img", "img2", true},
+		{"leading zeros tie-break", "img2", "img02", true},
+		{"leading zeros reverse", "img02", "img2", false},
+		{"number value beats zeros", "img002", "img10", true},
+		{"case tie-break", "Apple", "apple", true},
+		{"equal strings", "a1", "a1", false},
+		{"digits before letters", "1abc", "abc", true},
+		{"large numbers", "v99999999999999999999", "v100000000000000000000", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := keyLess(t, "natural", tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("compareNatural(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// ErrLineTooLong is returned when a line is longer than --max-line-length
+var ErrLineTooLong = messages.New("line exceeds --max-line-length")
+
+// Format is how the input was written, so the output can be written back the same way
+type Format struct {
+	CRLF         bool // The first line ends with "\r\n"
+	BOM          bool // The input starts with a UTF-8 byte order mark
+	FinalNewline bool // The last line ends with a terminator, or the input is empty
+}
+
+// byteOrderMark is the UTF-8 encoding of U+FEFF
+const byteOrderMark = "\xEF\xBB\xBF"
+
+// input is a file being read together with its format
+type input struct {
+	reader *bufio.Reader
+	name   string
+	format Format
+	lines  int  // Number of lines read
+	eof    bool // The end of the input is reached
+}
+
+// newInput drops the byte order mark at the start of r and detects the line endings from
+// the first line. A first line longer than the read buffer counts as LF terminated
+func newInput(r io.Reader) (*input, error) {
+	in := &input{
+		reader: bufio.NewReaderSize(r, 64*1024),
+		name:   inputName(r),
+		format: Format{FinalNewline: true},
+	}
+
+	buffered, err := in.reader.Peek(in.reader.Size())
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(buffered, []byte(byteOrderMark)) {
+		in.format.BOM = true
+		buffered = buffered[len(byteOrderMark):]
+		if _, err := in.reader.Discard(len(byteOrderMark)); err != nil {
+			return nil, err
+		}
+	}
+
+	if end := bytes.IndexByte(buffered, '\n'); end > 0 && buffered[end-1] == '\r' {
+		in.format.CRLF = true
+	}
+	return in, nil
+}
+
+// readLines reads all lines, or NUL-terminated records with -z, and notes whether the last one is terminated
+// Lines of any length are read; with --max-line-length the error names the file and the line
+func (in *input) readLines(options *p.KeySort) ([]string, error) {
+	var lines []string
+	for {
+		line, ok, err := in.next(options)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// next reads the next line, see readLines; ok is false at the end of the input
+func (in *input) next(options *p.KeySort) (string, bool, error) {
+	if in.eof {
+		return "", false, nil
+	}
+
+	delim := byte('\n')
+	if options.ZeroTerminated {
+		delim = 0
+	}
+
+	line, err := readLine(in.reader, delim, options.MaxLineLength)
+	if errors.Is(err, ErrLineTooLong) {
+		return "", false, messages.Errorf("%s:%d: %w of %d bytes", in.name, in.lines+1, err, options.MaxLineLength)
+	}
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+
+	if err == io.EOF {
+		in.eof = true
+		in.format.FinalNewline = len(line) == 0
+		if len(line) == 0 {
+			return "", false, nil
+		}
+	}
+	in.lines++
+	return trimLine(line, delim), true, nil
+}
+
+// readLine reads up to and including delim, growing past the reader buffer as needed
+// With maxLength above zero it stops as soon as the line without delim is longer
+func readLine(reader *bufio.Reader, delim byte, maxLength int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice(delim)
+		line = append(line, chunk...)
+
+		length := len(line)
+		if err == nil {
+			length--
+		}
+		if maxLength > 0 && length > maxLength {
+			return nil, ErrLineTooLong
+		}
+
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// trimLine drops the delimiter, and the carriage return before a newline
+func trimLine(line []byte, delim byte) string {
+	if len(line) > 0 && line[len(line)-1] == delim {
+		line = line[:len(line)-1]
+		if delim == '\n' && len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+	}
+	return string(line)
+}
+
+// inputName returns the file name of r for messages, or "-" for standard input and readers without one
+// It is the one place naming standard input, whose os.File name is /dev/stdin
+func inputName(r io.Reader) string {
+	if file, ok := r.(*os.File); ok && file == os.Stdin {
+		return "-"
+	}
+	if named, ok := r.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return "-"
+}
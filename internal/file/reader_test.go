@@ -0,0 +1,125 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	longLine := strings.Repeat("x", 1<<20)
+
+	tests := []struct {
+		name     string
+		content  string
+		options  *args.KeySort
+		expected []string
+	}{
+		{"line longer than 64 KiB", "b\n" + longLine + "\na\n", &args.KeySort{}, []string{"b", longLine, "a"}},
+		{"empty lines kept", "a\n\nb\n", &args.KeySort{}, []string{"a", "", "b"}},
+		{"no final newline", "a\nb", &args.KeySort{}, []string{"a", "b"}},
+		{"carriage returns dropped", "a\r\nb\r\n", &args.KeySort{}, []string{"a", "b"}},
+		{"zero terminated", "a\nb\x00c\x00", &args.KeySort{ZeroTerminated: true}, []string{"a\nb", "c"}},
+		{"within limit", "abc\nde\n", &args.KeySort{MaxLineLength: 3}, []string{"abc", "de"}},
+		{"empty input", "", &args.KeySort{}, nil},
+		{"byte order mark dropped", "\xEF\xBB\xBF2\r\n1\r\n", &args.KeySort{}, []string{"2", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := newInput(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("newInput() error = %v", err)
+			}
+
+			result, err := in.readLines(tt.options)
+			if err != nil {
+				t.Fatalf("readLines() error = %v", err)
+			}
+
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Expected %d lines, got %d", len(tt.expected), len(result))
+			}
+			for i, line := range result {
+				if line != tt.expected[i] {
+					t.Errorf("At index %d: expected %.20q, got %.20q", i, tt.expected[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestReadLinesMaxLength(t *testing.T) {
+	file := openTestFile(t, "short\n"+strings.Repeat("y", 100000)+"\n")
+
+	in, err := newInput(file)
+	if err != nil {
+		t.Fatalf("newInput() error = %v", err)
+	}
+
+	_, err = in.readLines(&args.KeySort{MaxLineLength: 1000})
+	if !errors.Is(err, ErrLineTooLong) {
+		t.Fatalf("expected ErrLineTooLong, got %v", err)
+	}
+
+	expected := file.Name() + ":2: "
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("expected error to start with %q, got %q", expected, err.Error())
+	}
+}
+
+func TestReadLinesFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Format
+	}{
+		{"LF", "b\na\n", Format{FinalNewline: true}},
+		{"CRLF", "b\r\na\r\n", Format{CRLF: true, FinalNewline: true}},
+		{"byte order mark", "\xEF\xBB\xBFb\na\n", Format{BOM: true, FinalNewline: true}},
+		{"no final newline", "b\r\na", Format{CRLF: true}},
+		{"carriage return inside line", "b\ra\n", Format{FinalNewline: true}},
+		{"empty input", "", Format{FinalNewline: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := newInput(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("newInput() error = %v", err)
+			}
+			if _, err := in.readLines(&args.KeySort{}); err != nil {
+				t.Fatalf("readLines() error = %v", err)
+			}
+
+			if in.format != tt.expected {
+				t.Errorf("Expected format %+v, got %+v", tt.expected, in.format)
+			}
+		})
+	}
+}
+
+func TestInputName(t *testing.T) {
+	file := openTestFile(t, "a\n")
+
+	tests := []struct {
+		name     string
+		reader   io.Reader
+		expected string
+	}{
+		{"file", file, file.Name()},
+		{"standard input", os.Stdin, "-"},
+		{"standard input with a context", contextReader{ctx: context.Background(), reader: os.Stdin}, "-"},
+		{"reader without a name", strings.NewReader("a\n"), "-"},
+	}
+
+	for _, tt := range tests {
+		if name := inputName(tt.reader); name != tt.expected {
+			t.Errorf("%s: inputName() = %q, expected %q", tt.name, name, tt.expected)
+		}
+	}
+}
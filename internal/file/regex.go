@@ -0,0 +1,50 @@
+package file
+
+import (
+	p "github.com/rzmsq/sort_utility/internal/args"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// ErrNoMatch is returned when a line does not match --key-regex and --key-regex-nomatch=error is set
+var ErrNoMatch = messages.New("line does not match --key-regex")
+
+// regexGroup returns the submatch holding the key: the group named "key",
+// otherwise the first group, otherwise the whole match
+func regexGroup(options *p.KeySort) int {
+	if index := options.KeyRegex.SubexpIndex("key"); index >= 0 {
+		return index
+	}
+	if options.KeyRegex.NumSubexp() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// regexRecords makes typed records whose only field is the text the regexp extracted
+// Lines without a match get a missing value, placed by --key-regex-nomatch
+// Error line numbers count the offset lines before the first one
+func regexRecords(lines []string, offset int, options *p.KeySort) ([]record, error) {
+	records := make([]record, len(lines))
+	for i, line := range lines {
+		rec, ok := regexRecord(line, options)
+		if !ok && options.KeyRegexNoMatch == p.InvalidError {
+			return nil, messages.Errorf("%w: line %d: %s", ErrNoMatch, offset+i+1, line)
+		}
+		records[i] = rec
+	}
+	return records, nil
+}
+
+// regexRecord makes the record of one line; ok is false when the regexp does not match
+func regexRecord(line string, options *p.KeySort) (record, bool) {
+	group := regexGroup(options)
+	rec := record{line: line, fields: []string{""}, kinds: []valueKind{kindMissing}}
+
+	match := options.KeyRegex.FindStringSubmatchIndex(line)
+	if match == nil || match[2*group] < 0 {
+		return rec, false
+	}
+	rec.fields[0] = line[match[2*group]:match[2*group+1]]
+	rec.kinds[0] = kindString
+	return rec, true
+}
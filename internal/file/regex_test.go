@@ -0,0 +1,108 @@
+package file
+
+import (
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegexGroup(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected int
+	}{
+		{`\d+`, 0},
+		{`id=(\d+)`, 1},
+		{`(\w+) took (?P<key>\d+)ms`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			result := regexGroup(&args.KeySort{KeyRegex: regexp.MustCompile(tt.pattern)})
+			if result != tt.expected {
+				t.Errorf("regexGroup(%q) = %d, want %d", tt.pattern, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortFileKeyRegex(t *testing.T) {
+	content := "GET /b took 120ms\nno timing here\nGET /a took 9ms\nPOST /c took 1000ms\n"
+
+	tests := []struct {
+		name          string
+		options       *args.KeySort
+		expectedLines []string
+		expectError   error
+	}{
+		{
+			name: "numeric first group, unmatched last",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true,
+				KeyRegex: regexp.MustCompile(`took (\d+)ms`)},
+			expectedLines: []string{"GET /a took 9ms", "GET /b took 120ms", "POST /c took 1000ms", "no timing here"},
+		},
+		{
+			name: "named group, unmatched first",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, KeyRegexNoMatch: args.InvalidFirst,
+				KeyRegex: regexp.MustCompile(`(GET|POST) (?P<key>/\w+)`)},
+			expectedLines: []string{"no timing here", "GET /a took 9ms", "GET /b took 120ms", "POST /c took 1000ms"},
+		},
+		{
+			name: "human numeric on whole match",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, HumanNumeric: true,
+				KeyRegex: regexp.MustCompile(`\d+`)},
+			expectedLines: []string{"GET /a took 9ms", "GET /b took 120ms", "POST /c took 1000ms", "no timing here"},
+		},
+		{
+			name: "unmatched error",
+			options: &args.KeySort{SortByColumn: true, ColumnNumber: 1, KeyRegexNoMatch: args.InvalidError,
+				KeyRegex: regexp.MustCompile(`took (\d+)ms`)},
+			expectError: ErrNoMatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SortFile(openTestFile(t, content), tt.options)
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Errorf("expected error %v, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SortFile() error = %v", err)
+			}
+
+			if len(result) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d lines, got %d", len(tt.expectedLines), len(result))
+			}
+			for i, line := range result {
+				if line != tt.expectedLines[i] {
+					t.Errorf("At index %d: expected %q, got %q", i, tt.expectedLines[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestSortFileKeyRegexHeader(t *testing.T) {
+	options := &args.KeySort{SortByColumn: true, ColumnNumber: 1, Numeric: true, HeaderLines: 1, FooterLines: 1,
+		KeyRegexNoMatch: args.InvalidError, KeyRegex: regexp.MustCompile(`took (\d+)ms`)}
+	expected := []string{"LOG HEADER", "GET /a took 9ms", "GET /b took 120ms", "END"}
+
+	result, err := SortFile(openTestFile(t, "LOG HEADER\nGET /b took 120ms\nGET /a took 9ms\nEND\n"), options)
+	if err != nil {
+		t.Fatalf("SortFile() error = %v", err)
+	}
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+
+	_, err = SortFile(openTestFile(t, "LOG HEADER\nGET /b took 120ms\nno timing here\nEND\n"), options)
+	if !errors.Is(err, ErrNoMatch) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected ErrNoMatch on line 3, got %v", err)
+	}
+}
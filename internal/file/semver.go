@@ -0,0 +1,127 @@
+package file
+
+import (
+	"strings"
+)
+
+// semVer is a parsed semantic version; build metadata is dropped as it has no precedence
+type semVer struct {
+	core       [3]string // Major, minor and patch without leading zeros
+	prerelease []string  // Dot-separated pre-release identifiers
+}
+
+// parseSemVer parses a SemVer 2.0 version, optionally prefixed with "v" when allowV is set
+func parseSemVer(s string, allowV bool) (semVer, bool) {
+	if allowV {
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	}
+
+	s, build, hasBuild := strings.Cut(s, "+")
+	if hasBuild && !validIdentifiers(build, false) {
+		return semVer{}, false
+	}
+
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+	if hasPrerelease && !validIdentifiers(prerelease, true) {
+		return semVer{}, false
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return semVer{}, false
+	}
+
+	var version semVer
+	for i, part := range parts {
+		if !isNumericIdentifier(part) {
+			return semVer{}, false
+		}
+		version.core[i] = part
+	}
+	if hasPrerelease {
+		version.prerelease = strings.Split(prerelease, ".")
+	}
+	return version, true
+}
+
+// validIdentifiers checks dot-separated identifiers; numeric pre-release identifiers may not have leading zeros
+func validIdentifiers(s string, prerelease bool) bool {
+	for _, identifier := range strings.Split(s, ".") {
+		if identifier == "" {
+			return false
+		}
+		for _, r := range identifier {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return false
+			}
+		}
+		if prerelease && isDigits(identifier) && !isNumericIdentifier(identifier) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumericIdentifier reports whether s is a number without leading zeros
+func isNumericIdentifier(s string) bool {
+	return isDigits(s) && (s == "0" || s[0] != '0')
+}
+
+// compareDigits compares two numbers without leading zeros of any length
+func compareDigits(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareSemVer returns -1, 0 or +1 following SemVer 2.0 precedence
+func compareSemVer(a, b semVer) int {
+	for i := range a.core {
+		if c := compareDigits(a.core[i], b.core[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A version without pre-release identifiers has higher precedence
+	if len(a.prerelease) == 0 && len(b.prerelease) == 0 {
+		return 0
+	}
+	if len(a.prerelease) == 0 {
+		return 1
+	}
+	if len(b.prerelease) == 0 {
+		return -1
+	}
+
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		idA, idB := a.prerelease[i], b.prerelease[i]
+		numA, numB := isDigits(idA), isDigits(idB)
+
+		var c int
+		switch {
+		case numA && numB:
+			c = compareDigits(idA, idB)
+		case numA:
+			c = -1
+		case numB:
+			c = 1
+		default:
+			c = strings.Compare(idA, idB)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	if len(a.prerelease) < len(b.prerelease) {
+		return -1
+	}
+	if len(a.prerelease) > len(b.prerelease) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package file
+
+import (
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		allowV bool
+		ok     bool
+	}{
+		{"release", "1.2.3", false, true},
+		{"pre-release", "1.0.0-alpha.1", false, true},
+		{"build metadata", "1.0.0+20130313144700", false, true},
+		{"pre-release and build", "1.0.0-beta+exp.sha.5114f85", false, true},
+		{"leading v rejected", "v1.2.3", false, false},
+		{"leading v allowed", "v1.2.3", true, true},
+		{"leading zero", "01.2.3", false, false},
+		{"leading zero in pre-release", "1.2.3-01", false, false},
+		{"missing patch", "1.2", false, false},
+		{"empty identifier", "1.2.3-alpha..1", false, false},
+		{"invalid character", "1.2.3-al_pha", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := parseSemVer(tt.input, tt.allowV)
+			if ok != tt.ok {
+				t.Errorf("parseSemVer(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSemVerLess(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"numeric major", "2.0.0", "10.0.0", true},
+		{"numeric patch", "1.0.10", "1.0.9", false},
+		{"pre-release before release", "1.0.0-alpha", "1.0.0", true},
+		{"release after pre-release", "1.0.0", "1.0.0-rc.1", false},
+		{"shorter pre-release first", "1.0.0-alpha", "1.0.0-alpha.1", true},
+		{"numeric before alphanumeric", "1.0.0-alpha.1", "1.0.0-alpha.beta", true},
+		{"alphanumeric identifiers", "1.0.0-beta", "1.0.0-rc.1", true},
+		{"numeric identifiers", "1.0.0-beta.2", "1.0.0-beta.11", true},
+		{"build metadata ignored", "1.0.0+b", "1.0.0+a", false},
+		{"invalid last", "latest", "1.0.0", false},
+		{"valid before invalid", "1.0.0", "latest", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := keyLess(t, "semver", tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("semVerLess(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
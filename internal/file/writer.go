@@ -0,0 +1,41 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+)
+
+// WriteLines writes each line followed by its terminator: NUL with -z, "\r\n" with
+// --preserve-line-endings when the input used CRLF, otherwise "\n"
+// The byte order mark of the input is written first unless --strip-bom is set, and
+// with --preserve-final-newline the last line is left unterminated when the input was
+// Once ctx is done writing stops and the context error is returned
+func WriteLines(ctx context.Context, w io.Writer, lines []string, format Format, options *p.KeySort) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	terminator := "\n"
+	switch {
+	case options.ZeroTerminated:
+		terminator = "\x00"
+	case options.PreserveLineEndings && format.CRLF:
+		terminator = "\r\n"
+	}
+
+	writer := bufio.NewWriter(contextWriter{ctx: ctx, writer: w})
+	if format.BOM && !options.StripBOM {
+		writer.WriteString(byteOrderMark)
+	}
+
+	for i, line := range lines {
+		writer.WriteString(line)
+		if i < len(lines)-1 || format.FinalNewline || !options.PreserveFinalNewline {
+			writer.WriteString(terminator)
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,57 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"github.com/rzmsq/sort_utility/internal/args"
+	"strings"
+	"testing"
+)
+
+func TestWriteLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		format   Format
+		options  *args.KeySort
+		expected string
+	}{
+		{"LF", []string{"a", "b"}, Format{FinalNewline: true}, &args.KeySort{}, "a\nb\n"},
+		{"CRLF converted", []string{"a", "b"}, Format{CRLF: true, FinalNewline: true}, &args.KeySort{}, "a\nb\n"},
+		{"CRLF preserved", []string{"a", "b"}, Format{CRLF: true, FinalNewline: true}, &args.KeySort{PreserveLineEndings: true}, "a\r\nb\r\n"},
+		{"LF preserved", []string{"a", "b"}, Format{FinalNewline: true}, &args.KeySort{PreserveLineEndings: true}, "a\nb\n"},
+		{"byte order mark kept", []string{"a", "b"}, Format{BOM: true, FinalNewline: true}, &args.KeySort{}, "\xEF\xBB\xBFa\nb\n"},
+		{"byte order mark stripped", []string{"a", "b"}, Format{BOM: true, FinalNewline: true}, &args.KeySort{StripBOM: true}, "a\nb\n"},
+		{"final newline added", []string{"a", "b"}, Format{}, &args.KeySort{}, "a\nb\n"},
+		{"missing final newline preserved", []string{"a", "b"}, Format{CRLF: true}, &args.KeySort{PreserveLineEndings: true, PreserveFinalNewline: true}, "a\r\nb"},
+		{"zero terminated", []string{"a", "b"}, Format{FinalNewline: true}, &args.KeySort{ZeroTerminated: true}, "a\x00b\x00"},
+		{"no lines", nil, Format{BOM: true}, &args.KeySort{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			if err := WriteLines(context.Background(), &output, tt.lines, tt.format, tt.options); err != nil {
+				t.Fatalf("WriteLines() error = %v", err)
+			}
+
+			if output.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestWriteLinesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var output strings.Builder
+	err := WriteLines(ctx, &output, []string{"a", "b"}, Format{FinalNewline: true}, &args.KeySort{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if output.Len() != 0 {
+		t.Errorf("expected no output, got %q", output.String())
+	}
+}
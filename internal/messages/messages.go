@@ -0,0 +1,173 @@
+// Package messages translates the text the command writes for people: diagnostics, --help and check results
+// Messages are written in English in the code and looked up by that text in the catalog of a language,
+// so a message missing from a catalog is written in English. There is no current language: the
+// language is passed to every function that writes text, and errors are English until rendered with Language.Error
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Language is a language of messages
+type Language string
+
+// Languages with a catalog
+const (
+	English Language = "en"
+	Russian Language = "ru"
+)
+
+// catalogs holds the translations of every language but English, keyed by the English text
+var catalogs = map[Language]map[string]string{
+	Russian: russian,
+}
+
+// Localizer is implemented by errors whose text can be written in any language
+type Localizer interface {
+	Localize(language Language) string
+}
+
+// ParseLanguage returns the language of a --lang value or a locale name such as ru_RU.UTF-8
+// The locales C and POSIX are English
+func ParseLanguage(name string) (Language, bool) {
+	code, _, _ := strings.Cut(name, ".")
+	code, _, _ = strings.Cut(code, "@")
+	code, _, _ = strings.Cut(code, "_")
+
+	switch strings.ToLower(code) {
+	case "en", "c", "posix":
+		return English, true
+	case "ru":
+		return Russian, true
+	}
+	return "", false
+}
+
+// FromEnvironment returns the language of the locale in LC_ALL, LC_MESSAGES or LANG, the first one set
+// An unset or unknown locale is English
+func FromEnvironment(getenv func(string) string) Language {
+	if _, locale := Locale(getenv); locale != "" {
+		if language, ok := ParseLanguage(locale); ok {
+			return language
+		}
+	}
+	return English
+}
+
+// Locale returns the first of LC_ALL, LC_MESSAGES and LANG that is set, and its value
+// Both are empty when none is set
+func Locale(getenv func(string) string) (string, string) {
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := getenv(variable); locale != "" {
+			return variable, locale
+		}
+	}
+	return "", ""
+}
+
+// Name returns the English name of the language, a message of the catalogs
+func (l Language) Name() string {
+	if l == Russian {
+		return "Russian"
+	}
+	return "English"
+}
+
+// Text returns message in the language
+func (l Language) Text(message string) string {
+	if translated, ok := catalogs[l][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf formats according to the format in the language; %w is formatted as %v
+// and error arguments are written in the language too
+func (l Language) Sprintf(format string, args ...any) string {
+	localized := make([]any, len(args))
+	for i, arg := range args {
+		if err, ok := arg.(error); ok {
+			arg = l.Error(err)
+		}
+		localized[i] = arg
+	}
+	return fmt.Sprintf(strings.ReplaceAll(l.Text(format), "%w", "%v"), localized...)
+}
+
+// Error returns the text of err in the language: a Localizer writes itself,
+// the text of any other error is looked up in the catalog as a whole
+func (l Language) Error(err error) string {
+	if localizer, ok := err.(Localizer); ok {
+		return localizer.Localize(l)
+	}
+	return l.Text(err.Error())
+}
+
+// New returns an error whose text is message, in English unless written with Language.Error
+func New(message string) error {
+	return &textError{message}
+}
+
+// Errorf works as fmt.Errorf; the text is in English unless written with Language.Error
+func Errorf(format string, args ...any) error {
+	return &formatError{format: format, args: args, err: fmt.Errorf(format, args...)}
+}
+
+// In returns err with its text written in language; errors.Is and errors.As see through it
+// Errors are English already, so for English err is returned as it is
+func In(language Language, err error) error {
+	if err == nil || language == English {
+		return err
+	}
+	return &languageError{language: language, err: err}
+}
+
+type textError struct {
+	message string
+}
+
+func (e *textError) Error() string {
+	return e.message
+}
+
+func (e *textError) Localize(language Language) string {
+	return language.Text(e.message)
+}
+
+type formatError struct {
+	format string
+	args   []any
+	err    error // The error made by fmt.Errorf, which wraps the %w argument
+}
+
+func (e *formatError) Error() string {
+	return e.err.Error()
+}
+
+func (e *formatError) Localize(language Language) string {
+	return language.Sprintf(e.format, e.args...)
+}
+
+func (e *formatError) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+// languageError is an error written in a fixed language, see In
+type languageError struct {
+	language Language
+	err      error
+}
+
+func (e *languageError) Error() string {
+	return e.language.Error(e.err)
+}
+
+func (e *languageError) Localize(language Language) string {
+	return language.Error(e.err)
+}
+
+func (e *languageError) Unwrap() error {
+	return e.err
+}
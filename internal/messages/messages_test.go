@@ -0,0 +1,226 @@
+package messages
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Language
+		ok       bool
+	}{
+		{"en", English, true},
+		{"ru", Russian, true},
+		{"ru_RU.UTF-8", Russian, true},
+		{"ru_UA@latin", Russian, true},
+		{"en_GB.UTF-8", English, true},
+		{"C", English, true},
+		{"POSIX", English, true},
+		{"C.UTF-8", English, true},
+		{"de_DE.UTF-8", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		language, ok := ParseLanguage(tt.name)
+		if language != tt.expected || ok != tt.ok {
+			t.Errorf("ParseLanguage(%q) = %q, %v, expected %q, %v", tt.name, language, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected Language
+	}{
+		{"unset", map[string]string{}, English},
+		{"LANG", map[string]string{"LANG": "ru_RU.UTF-8"}, Russian},
+		{"LC_MESSAGES over LANG", map[string]string{"LC_MESSAGES": "C", "LANG": "ru_RU.UTF-8"}, English},
+		{"LC_ALL over LC_MESSAGES", map[string]string{"LC_ALL": "ru_RU.UTF-8", "LC_MESSAGES": "en_US.UTF-8"}, Russian},
+		{"unknown locale", map[string]string{"LC_ALL": "de_DE.UTF-8", "LANG": "ru_RU.UTF-8"}, English},
+	}
+
+	for _, tt := range tests {
+		getenv := func(name string) string { return tt.env[name] }
+		if language := FromEnvironment(getenv); language != tt.expected {
+			t.Errorf("%s: FromEnvironment() = %q, expected %q", tt.name, language, tt.expected)
+		}
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	sentinel := New("invalid date")
+	err := Errorf("%w: line %d: %s", sentinel, 3, "soon")
+	if !errors.Is(err, sentinel) {
+		t.Errorf("Errorf does not wrap the %%w argument")
+	}
+
+	if expected := "invalid date: line 3: soon"; err.Error() != expected {
+		t.Errorf("English: expected %q, got %q", expected, err.Error())
+	}
+	if expected := "некорректная дата: строка 3: soon"; Russian.Error(err) != expected {
+		t.Errorf("Russian: expected %q, got %q", expected, Russian.Error(err))
+	}
+	if expected := "not in the catalog"; Russian.Text(expected) != expected {
+		t.Errorf("a message missing from the catalog is not written in English")
+	}
+}
+
+func TestIn(t *testing.T) {
+	sentinel := New("invalid date")
+	err := In(Russian, Errorf("%w: line %d: %s", sentinel, 3, "soon"))
+
+	if !errors.Is(err, sentinel) {
+		t.Errorf("In does not wrap the error")
+	}
+	if expected := "некорректная дата: строка 3: soon"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+	if expected := "invalid date: line 3: soon"; English.Error(err) != expected {
+		t.Errorf("English: expected %q, got %q", expected, English.Error(err))
+	}
+	if In(Russian, nil) != nil {
+		t.Errorf("In(nil) is not nil")
+	}
+}
+
+// TestCatalog looks for the messages of the module in the Russian catalog: the literal text given
+// to Language.Text and Language.Sprintf, to messages.New and messages.Errorf, and of option errors
+func TestCatalog(t *testing.T) {
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		for _, message := range fileMessages(file) {
+			if _, ok := russian[message.text]; !ok && translatable(message.text) {
+				t.Errorf("%s: %q has no Russian translation", fset.Position(message.pos), message.text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// message is the text of a message found in the source
+type message struct {
+	text string
+	pos  token.Pos
+}
+
+// fileMessages returns the messages of file whose text is a constant
+func fileMessages(file *ast.File) []message {
+	const messagesPath = "github.com/rzmsq/sort_utility/internal/messages"
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := pathpkg.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+
+	var found []message
+	add := func(expr ast.Expr) {
+		if text, ok := constantString(expr); ok {
+			found = append(found, message{text, expr.Pos()})
+		}
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			selector, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || len(node.Args) == 0 {
+				return true
+			}
+			var importPath string
+			if receiver, ok := selector.X.(*ast.Ident); ok && receiver.Obj == nil {
+				importPath = imports[receiver.Name]
+			}
+			switch selector.Sel.Name {
+			case "Text", "Sprintf":
+				if importPath == "" || importPath == messagesPath {
+					add(node.Args[0])
+				}
+			case "New", "Errorf":
+				if importPath == messagesPath {
+					add(node.Args[0])
+				}
+			}
+		case *ast.CompositeLit:
+			if name, ok := node.Type.(*ast.Ident); ok && name.Name == "optionError" && len(node.Elts) > 1 {
+				add(node.Elts[1])
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// untranslated matches the parts of a message that are the same in every language: verbs and long options
+var untranslated = regexp.MustCompile(`%[^a-zA-Z%]*[a-zA-Z%]|--[a-z][-a-z]*`)
+
+// translatable reports whether text has words besides verbs and long options
+func translatable(text string) bool {
+	return strings.ContainsFunc(untranslated.ReplaceAllString(text, ""), unicode.IsLetter)
+}
+
+// constantString returns the value of a string literal, a constant of the file or a sum of them
+func constantString(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind == token.STRING {
+			text, err := strconv.Unquote(expr.Value)
+			return text, err == nil
+		}
+	case *ast.BinaryExpr:
+		if expr.Op == token.ADD {
+			x, okX := constantString(expr.X)
+			y, okY := constantString(expr.Y)
+			return x + y, okX && okY
+		}
+	case *ast.ParenExpr:
+		return constantString(expr.X)
+	case *ast.Ident:
+		if expr.Obj == nil || expr.Obj.Kind != ast.Con {
+			return "", false
+		}
+		if spec, ok := expr.Obj.Decl.(*ast.ValueSpec); ok {
+			for i, name := range spec.Names {
+				if name.Name == expr.Name && i < len(spec.Values) {
+					return constantString(spec.Values[i])
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,144 @@
+package messages
+
+// russian is the Russian catalog
+var russian = map[string]string{
+	// Command line
+	"invalid argument":                              "недопустимый аргумент",
+	"invalid number":                                "недопустимое число",
+	"unknown option":                                "неизвестная опция",
+	"help requested":                                "запрошена справка",
+	"version requested":                             "запрошена версия",
+	"option requires an argument":                   "опции требуется аргумент",
+	"no such file or directory":                     "нет такого файла или каталога",
+	"unknown key type":                              "неизвестный тип ключа",
+	"unknown column":                                "неизвестная колонка",
+	"key type already registered":                   "тип ключа уже зарегистрирован",
+	"%w: field number is zero":                      "%w: номер поля равен нулю",
+	"%w for '%s'":                                   "%w для '%s'",
+	"%w: key type name %q":                          "%w: имя типа ключа %q",
+	"%w: character positions are not supported: %s": "%w: позиции символов не поддерживаются: %s",
+	"%w: conflicting key types: %s":                 "%w: несовместимые типы ключа: %s",
+	"%w: key option '%c': %s":                       "%w: опция ключа '%c': %s",
+	"unrecognized option '--%s'":                    "нераспознанная опция '--%s'",
+	"option '--%s' is ambiguous; possibilities: %s": "опция '--%s' неоднозначна; возможные варианты: %s",
+	"option '--%s' doesn't allow an argument":       "опция '--%s' не допускает аргумент",
+	"option '--%s' requires an argument":            "опции '--%s' требуется аргумент",
+	"invalid option -- '%c'":                        "недопустимая опция -- '%c'",
+	"option requires an argument -- '%c'":           "опции требуется аргумент -- '%c'",
+	"extra operand '%s'":                            "лишний операнд '%s'",
+	"cannot read: '': %w":                           "не удается прочитать: '': %w",
+	"conflicting sort options: only one of -n, -M, -h, --date, --ip, --semver, --natural, --duration, --sort can be used": "несовместимые опции сортировки: можно указать только одну из -n, -M, -h, --date, --ip, --semver, --natural, --duration, --sort",
+	"conflicting input formats: only one of --csv, --tsv, --jsonl, --json can be used":                                    "несовместимые форматы ввода: можно указать только один из --csv, --tsv, --jsonl, --json",
+	"--key-regex cannot be used with -k, --csv, --tsv, --jsonl or --json":                                                 "--key-regex нельзя использовать вместе с -k, --csv, --tsv, --jsonl или --json",
+	"--columns cannot be used with field keys, --key-regex, --csv, --tsv, --jsonl or --json":                              "--columns нельзя использовать вместе с ключами по колонкам, --key-regex, --csv, --tsv, --jsonl или --json",
+	"-z cannot be used with --csv, --tsv or --json":                                                                       "-z нельзя использовать вместе с --csv, --tsv или --json",
+	"--json-missing requires --jsonl or --json":                                                                           "--json-missing требует --jsonl или --json",
+	"--header and --footer cannot be used with --json":                                                                    "--header и --footer нельзя использовать вместе с --json",
+	"column ranges in -k cannot be used with --csv, --tsv, --jsonl, --json, --key-regex or --columns: %d,%d":              "диапазоны колонок в -k нельзя использовать вместе с --csv, --tsv, --jsonl, --json, --key-regex или --columns: %d,%d",
+	"column names in -k require --csv or --tsv: %s":                                                                       "имена колонок в -k требуют --csv или --tsv: %s",
+	"JSON paths in -k require --jsonl or --json: %s":                                                                      "JSON-пути в -k требуют --jsonl или --json: %s",
+	"keys in --jsonl and --json mode must be JSON paths: %d":                                                              "ключи в режимах --jsonl и --json должны быть JSON-путями: %d",
+	"check mode (-c) cannot be used with -r or -u":                                                                        "режим проверки (-c) нельзя использовать вместе с -r или -u",
+
+	// Input
+	"invalid JSON":                         "некорректный JSON",
+	"invalid date":                         "некорректная дата",
+	"invalid key":                          "некорректный ключ",
+	"line does not match --key-regex":      "строка не соответствует --key-regex",
+	"line exceeds --max-line-length":       "строка длиннее --max-line-length",
+	"%s:%d: %w of %d bytes":                "%s:%d: %w (максимум %d байт)",
+	"%w: line %d: %s":                      "%w: строка %d: %s",
+	"%w: line %d: %v":                      "%w: строка %d: %v",
+	"%w: element %d: %v":                   "%w: элемент %d: %v",
+	"%w: expected an array":                "%w: ожидается массив",
+	"%w: unexpected data after the array":  "%w: лишние данные после массива",
+	"unexpected data after the JSON value": "лишние данные после значения JSON",
+	"cannot read: %s: %w":                  "не удается прочитать: %s: %w",
+	"failed to open file %s: %w":           "не удается открыть файл %s: %w",
+	"cannot merge --csv, --tsv or --json input or keep --header and --footer lines": "нельзя слить ввод --csv, --tsv или --json или сохранить строки --header и --footer",
+	"File is sorted":     "Файл отсортирован",
+	"File is not sorted": "Файл не отсортирован",
+
+	// Command
+	"cannot read":              "не удается прочитать",
+	"read failed":              "ошибка чтения",
+	"write failed":             "ошибка записи",
+	"close failed":             "ошибка закрытия",
+	"disorder":                 "нарушен порядок",
+	"standard output":          "стандартный вывод",
+	"%s: internal error: %v\n": "%s: внутренняя ошибка: %v\n",
+	"Try '%s --help' for more information.\n": "По команде '%s --help' можно получить дополнительную информацию.\n",
+	"revision %s\n":           "ревизия %s\n",
+	"revision %s, modified\n": "ревизия %s, с изменениями\n",
+
+	// System errors
+	"is a directory":          "это каталог",
+	"permission denied":       "отказано в доступе",
+	"no space left on device": "на устройстве не осталось места",
+	"broken pipe":             "обрыв канала",
+	"context canceled":        "операция прервана",
+
+	// Debug
+	"^ no match for key": "^ нет значения ключа",
+	"keys of --csv, --tsv, --jsonl and --json input are not underlined":            "ключи ввода --csv, --tsv, --jsonl и --json не подчеркиваются",
+	"option '-b' has no effect: only --csv and --tsv fields can start with blanks": "опция '-b' ни на что не влияет: с пробелов могут начинаться только поля --csv и --tsv",
+	"key %d is %s and spans multiple fields":                                       "ключ %d имеет тип %s и занимает несколько полей",
+	"key %d is missing from every line":                                            "ключа %d нет ни в одной строке",
+	"key %d has no valid %s value in any line; the values are compared as text":    "ни в одной строке ключ %d не является корректным значением типа %s; значения сравниваются как текст",
+	"text ordering performed using simple byte comparison":                         "текст упорядочивается простым сравнением байтов",
+	"using the %s locale from %s":                                                  "используется локаль %s из %s",
+	"no locale is set in LC_ALL, LC_MESSAGES or LANG":                              "локаль не задана в LC_ALL, LC_MESSAGES и LANG",
+	"messages in %s":                  "сообщения на языке: %s",
+	"messages in %s, set with --lang": "сообщения на языке: %s, задан опцией --lang",
+	"English":                         "английский",
+	"Russian":                         "русский",
+	"annotate the part of each line used to sort, and warn about questionable usage to stderr": "подчеркнуть часть каждой строки, по которой идет сортировка, и предупредить в stderr о сомнительных опциях",
+
+	// Help
+	"Usage: %s [OPTION]... [FILE]\n" +
+		"Write the sorted lines of FILE to standard output.\n" +
+		"With no FILE, or when FILE is -, read standard input.\n\n": "Использование: %s [ОПЦИЯ]... [ФАЙЛ]\n" +
+		"Выводит отсортированные строки ФАЙЛА на стандартный вывод.\n" +
+		"Без ФАЙЛА или если ФАЙЛ — -, читается стандартный ввод.\n\n",
+	"KEYDEF is N[:TYPE[=ARG]], where N is a column number, a negative number from the end,\n" +
+		"a CSV column name or a JSON path, or F1[,F2][OPTS] with OPTS among b, h, M, n and r\n": "KEYDEF — это N[:ТИП[=АРГ]], где N — номер колонки, отрицательный номер с конца,\n" +
+		"имя колонки CSV или JSON-путь, либо F1[,F2][ОПЦИИ], где ОПЦИИ — из b, h, M, n и r\n",
+	"ignore leading blanks":                                           "игнорировать ведущие пробелы",
+	"check whether the input is sorted, do not sort":                  "проверить, отсортирован ли ввод, без сортировки",
+	"compare human readable sizes (2K, 1G)":                           "сравнивать размеры в удобном для чтения виде (2K, 1G)",
+	"sort by a key: N[:TYPE[=ARG]] or F1[,F2][OPTS]; may be repeated": "сортировать по ключу: N[:ТИП[=АРГ]] или F1[,F2][ОПЦИИ]; можно повторять",
+	"compare month names (JAN < ... < DEC)":                           "сравнивать названия месяцев (JAN < ... < DEC)",
+	"compare numeric values":                                          "сравнивать числовые значения",
+	"reverse the result of comparisons":                               "обратить результат сравнения",
+	"output only the first of equal lines":                            "выводить только первую из равных строк",
+	"lines end with NUL, not newline":                                 "строки завершаются NUL, а не переводом строки",
+	"compare with the key type TYPE: lexical, numeric, month, human, date, ip, semver, natural, duration": "сравнивать по типу ключа ТИП: lexical, numeric, month, human, date, ip, semver, natural, duration",
+	"compare dates, in the Go time LAYOUT or detected":                                                    "сравнивать даты в формате Go LAYOUT или с автоопределением",
+	"time zone of dates without an offset":                                                                "часовой пояс для дат без смещения",
+	"put unparsable dates first, last or report an error":                                                 "нераспознанные даты — в начало, в конец или ошибка",
+	"compare IP addresses and CIDR prefixes":                                                              "сравнивать IP-адреса и CIDR-префиксы",
+	"compare semantic versions":                                                                           "сравнивать семантические версии",
+	"accept a leading v in semantic versions":                                                             "допускать префикс v в семантических версиях",
+	"compare numbers inside text by value":                                                                "сравнивать числа внутри текста по значению",
+	"compare durations (250ms, 1h30m, 3d, HH:MM:SS)":                                                      "сравнивать длительности (250ms, 1h30m, 3d, ЧЧ:ММ:СС)",
+	"input is CSV":                                                                "ввод в формате CSV",
+	"input is TSV":                                                                "ввод в формате TSV",
+	"CSV or TSV input has no header row":                                          "в CSV или TSV нет строки заголовка",
+	"input is JSON Lines, keys are JSON paths":                                    "ввод в формате JSON Lines, ключи — JSON-пути",
+	"input is a JSON array, keys are JSON paths":                                  "ввод — JSON-массив, ключи — JSON-пути",
+	"put null and missing JSON values first or last":                              "null и отсутствующие значения JSON — в начало или в конец",
+	"fail on lines longer than N bytes":                                           "ошибка на строках длиннее N байт",
+	"keep the first N lines in place":                                             "оставить первые N строк на месте",
+	"keep the last N lines in place":                                              "оставить последние N строк на месте",
+	"take the key from a match of REGEXP":                                         "брать ключ из совпадения REGEXP",
+	"put lines REGEXP does not match first, last or report an error":              "строки без совпадения с REGEXP — в начало, в конец или ошибка",
+	"sort by the Nth column from the end of the line":                             "сортировать по N-й колонке с конца строки",
+	"sort by fixed-width column ranges":                                           "сортировать по диапазонам колонок фиксированной ширины",
+	"count --columns positions in byte, rune or width":                            "единица позиций --columns: byte, rune или width",
+	"end lines with CRLF when the input does":                                     "завершать строки CRLF, если так во вводе",
+	"do not write the UTF-8 byte order mark of the input":                         "не выводить UTF-8 BOM входного файла",
+	"leave the last line unterminated when the input does":                        "не завершать последнюю строку, если так во вводе",
+	"language of messages: en or ru, by default from LC_ALL, LC_MESSAGES or LANG": "язык сообщений: en или ru, по умолчанию из LC_ALL, LC_MESSAGES или LANG",
+	"display this help and exit":                                                  "показать эту справку и выйти",
+	"output version information and exit":                                         "показать версию и выйти",
+}
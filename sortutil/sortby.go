@@ -0,0 +1,102 @@
+package sortutil
+
+import (
+	"slices"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	f "github.com/rzmsq/sort_utility/internal/file"
+)
+
+// KeyFunc is a sort key of Go values for SortBy
+type KeyFunc[T any] struct {
+	Text    func(T) string // Returns the key text of an item
+	Type    string         // Key type as after ":" in -k, such as "n", "human" or "date=2006-01-02"; empty is lexical
+	Reverse bool           // Sort by this key in descending order
+}
+
+// By returns a key comparing the text of items with the key type keyType
+func By[T any](text func(T) string, keyType string) KeyFunc[T] {
+	return KeyFunc[T]{Text: text, Type: keyType}
+}
+
+// Desc returns the key sorting in descending order
+func (k KeyFunc[T]) Desc() KeyFunc[T] {
+	k.Reverse = true
+	return k
+}
+
+// SortBy sorts items by the keys in priority order with the comparators of the command
+// Each key text is taken and parsed once per item. The sort is not stable, see SortStableBy
+func SortBy[T any](items []T, keys ...KeyFunc[T]) error {
+	return sortBy(items, keys, false)
+}
+
+// SortStableBy sorts like SortBy and keeps items with equal keys in their original order
+func SortStableBy[T any](items []T, keys ...KeyFunc[T]) error {
+	return sortBy(items, keys, true)
+}
+
+func sortBy[T any](items []T, keys []KeyFunc[T], stable bool) error {
+	parsers := make([]func(text string) any, len(keys))
+	comparers := make([]func(a, b any) int, len(keys))
+	for i, key := range keys {
+		parse, compare, err := keyOrder(key.Type)
+		if err != nil {
+			return err
+		}
+		parsers[i], comparers[i] = parse, compare
+	}
+
+	values := make([][]any, len(items))
+	for i, item := range items {
+		values[i] = make([]any, len(keys))
+		for k, key := range keys {
+			values[i][k] = parsers[k](key.Text(item))
+		}
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	compare := func(i, j int) int {
+		for k, compareKey := range comparers {
+			c := compareKey(values[i][k], values[j][k])
+			if keys[k].Reverse {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	if stable {
+		slices.SortStableFunc(order, compare)
+	} else {
+		slices.SortFunc(order, compare)
+	}
+
+	sorted := make([]T, len(items))
+	for i, index := range order {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+// keyOrder returns how texts of the key type given as after ":" in -k are parsed and compared
+func keyOrder(keyType string) (func(text string) any, func(a, b any) int, error) {
+	key, err := p.ParseKeyType(keyType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := Options{Keys: []Key{key}}
+	if err := options.Validate(); err != nil {
+		return nil, nil, err
+	}
+	parse, compare := f.KeyOrder(key, &options)
+	return parse, compare, nil
+}
@@ -0,0 +1,78 @@
+package sortutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type release struct {
+	Name    string
+	Version string
+	Size    string
+}
+
+func TestSortBy(t *testing.T) {
+	releases := []release{
+		{"api", "1.0.0", "2K"},
+		{"web", "1.0.0-rc.1", "1M"},
+		{"cli", "1.0.0-alpha", "500"},
+		{"db", "1.0.0-alpha.1", "2K"},
+	}
+	name := func(r release) string { return r.Name }
+	version := func(r release) string { return r.Version }
+	size := func(r release) string { return r.Size }
+
+	tests := []struct {
+		name     string
+		keys     []KeyFunc[release]
+		expected []string
+	}{
+		{"lexical", []KeyFunc[release]{By(name, "")}, []string{"api", "cli", "db", "web"}},
+		{"semver", []KeyFunc[release]{By(version, "semver")}, []string{"cli", "db", "web", "api"}},
+		{"human size then name", []KeyFunc[release]{By(size, "h"), By(name, "")}, []string{"cli", "api", "db", "web"}},
+		{"descending key", []KeyFunc[release]{By(size, "human").Desc(), By(name, "")}, []string{"web", "api", "db", "cli"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := append([]release(nil), releases...)
+			if err := SortBy(items, tt.keys...); err != nil {
+				t.Fatalf("SortBy() error = %v", err)
+			}
+
+			names := make([]string, len(items))
+			for i, item := range items {
+				names[i] = item.Name
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestSortStableBy(t *testing.T) {
+	items := []release{{"b", "", "2K"}, {"a", "", "1K"}, {"c", "", "2048"}, {"d", "", "1K"}}
+	if err := SortStableBy(items, By(func(r release) string { return r.Size }, "h")); err != nil {
+		t.Fatalf("SortStableBy() error = %v", err)
+	}
+
+	expected := []string{"a", "d", "b", "c"}
+	for i, item := range items {
+		if item.Name != expected[i] {
+			t.Errorf("At index %d: expected %s, got %s", i, expected[i], item.Name)
+		}
+	}
+}
+
+func TestSortByUnknownType(t *testing.T) {
+	items := []string{"b", "a"}
+	err := SortBy(items, By(func(s string) string { return s }, "colour"))
+	if !errors.Is(err, ErrUnknownKeyType) {
+		t.Errorf("expected ErrUnknownKeyType, got %v", err)
+	}
+	if items[0] != "b" {
+		t.Errorf("items changed after an error: %v", items)
+	}
+}
@@ -0,0 +1,178 @@
+// Package sortutil sorts text records the way the sort_utility command does
+//
+// A Sorter is built once from Options and can then sort or check any number of inputs:
+//
+//	sorter, err := sortutil.New(sortutil.Options{Numeric: true, Keys: []sortutil.Key{{ColumnNumber: 2}}})
+//	if err != nil {
+//		return err
+//	}
+//	err = sorter.Sort(os.Stdin, os.Stdout)
+//
+// The API follows semantic versioning, Version is the version of this package
+package sortutil
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	p "github.com/rzmsq/sort_utility/internal/args"
+	f "github.com/rzmsq/sort_utility/internal/file"
+	"github.com/rzmsq/sort_utility/internal/messages"
+)
+
+// Version is the version of the sortutil API
+const Version = "1.0.0"
+
+// Options are the sort options, the same ones the command-line flags set
+type Options = p.KeySort
+
+// Key is a single sort key, as given with -k
+type Key = p.Key
+
+// KeyType orders the values of a sort key, see RegisterKeyType
+type KeyType = p.KeyType
+
+// KeyTypeFactory makes the key type for ARG in -k N:TYPE=ARG and Key.TypeArg
+type KeyTypeFactory = p.KeyTypeFactory
+
+// Format is how an input was written: line endings, byte order mark and final newline
+type Format = f.Format
+
+// Disorder is the first record of an input that is out of order, see FirstDisorder
+type Disorder = f.Disorder
+
+// Placement of values that cannot be parsed or are missing
+const (
+	InvalidLast  = p.InvalidLast  // Unparsable values go after all valid ones
+	InvalidFirst = p.InvalidFirst // Unparsable values go before all valid ones
+	InvalidError = p.InvalidError // Unparsable values abort the sort
+)
+
+// Units of --columns positions
+const (
+	UnitByte  = p.UnitByte  // Positions count bytes
+	UnitRune  = p.UnitRune  // Positions count characters
+	UnitWidth = p.UnitWidth // Positions count terminal cells, East Asian wide characters take two
+)
+
+// Errors returned for invalid options and input, compare with errors.Is
+var (
+	ErrInvalidArgument  = p.ErrInvalidArgument
+	ErrUnknownKeyType   = p.ErrUnknownKeyType
+	ErrUnknownColumn    = p.ErrUnknownColumn
+	ErrDuplicateKeyType = p.ErrDuplicateKeyType
+	ErrInvalidDate      = f.ErrInvalidDate
+	ErrInvalidJSON      = f.ErrInvalidJSON
+	ErrNoMatch          = f.ErrNoMatch
+	ErrLineTooLong      = f.ErrLineTooLong
+	ErrMergeFormat      = f.ErrMergeFormat
+)
+
+// RegisterKeyType adds a key type that keys select with Key.Type, Options.Type or
+// -k N:NAME[=ARG] on the command line. It is used by sorting and check mode alike
+// Register types before the Sorters using them are built, usually from an init function
+func RegisterKeyType(name string, factory KeyTypeFactory) error {
+	return p.RegisterKeyType(name, factory)
+}
+
+// Sorter sorts inputs with a fixed set of options; it is safe for concurrent use
+// Its errors are written in the language of Options.Language, en or ru, English when it is empty
+type Sorter struct {
+	options Options
+}
+
+// New validates the options and returns a Sorter using them
+// Without a column or keys the first column is the key. IsSorted is ignored, use Check instead
+func New(options Options) (*Sorter, error) {
+	options.Keys = append([]Key(nil), options.Keys...)
+	options.IsSorted = false
+	if err := options.Validate(); err != nil {
+		return nil, messages.In(options.MessageLanguage(), err)
+	}
+	return &Sorter{options: options}, nil
+}
+
+// Lines reads all records from r and returns them sorted, together with the format of r
+// The lines hold neither the terminator nor the byte order mark
+func (s *Sorter) Lines(r io.Reader) ([]string, Format, error) {
+	return s.LinesContext(context.Background(), r)
+}
+
+// LinesContext works as Lines and stops with the context error once ctx is done
+func (s *Sorter) LinesContext(ctx context.Context, r io.Reader) ([]string, Format, error) {
+	lines, format, err := f.Sort(ctx, r, s.copyOptions())
+	return lines, format, s.localize(err)
+}
+
+// Sort reads all records from r and writes them sorted to w, in the format of r
+// as far as the line ending options allow
+func (s *Sorter) Sort(r io.Reader, w io.Writer) error {
+	return s.SortContext(context.Background(), r, w)
+}
+
+// SortContext works as Sort and stops reading, sorting or writing with the
+// context error once ctx is done
+func (s *Sorter) SortContext(ctx context.Context, r io.Reader, w io.Writer) error {
+	options := s.copyOptions()
+
+	lines, format, err := f.Sort(ctx, r, options)
+	if err != nil {
+		return s.localize(err)
+	}
+	return s.localize(f.WriteLines(ctx, w, lines, format, options))
+}
+
+// Check reads all records from r and reports whether they are already sorted
+func (s *Sorter) Check(r io.Reader) (bool, error) {
+	return s.CheckContext(context.Background(), r)
+}
+
+// CheckContext works as Check and stops with the context error once ctx is done
+func (s *Sorter) CheckContext(ctx context.Context, r io.Reader) (bool, error) {
+	sorted, err := f.Check(ctx, r, s.copyOptions())
+	return sorted, s.localize(err)
+}
+
+// FirstDisorder reads all records from r and returns the first one that is out of order,
+// or nil when they are sorted; it stops with the context error once ctx is done
+func (s *Sorter) FirstDisorder(ctx context.Context, r io.Reader) (*Disorder, error) {
+	disorder, err := f.FirstDisorder(ctx, r, s.copyOptions())
+	return disorder, s.localize(err)
+}
+
+// All yields the records of r in sorted order one line at a time, without terminators
+// The records are sorted in memory, but the output is never collected. An error is yielded
+// as the last pair; once ctx is done reading and sorting stop with the context error
+func (s *Sorter) All(ctx context.Context, r io.Reader) iter.Seq2[string, error] {
+	return s.localizeSeq(f.All(ctx, r, s.copyOptions()))
+}
+
+// Merge yields the lines of readers, each already sorted with these options, in sorted order
+// It reads one line of every reader at a time, so inputs of any size can be merged
+// CSV, TSV and JSON array input and header or footer lines cannot be merged
+func (s *Sorter) Merge(ctx context.Context, readers ...io.Reader) iter.Seq2[string, error] {
+	return s.localizeSeq(f.Merge(ctx, readers, s.copyOptions()))
+}
+
+// copyOptions returns a copy of the options, so a sort cannot change the Sorter
+func (s *Sorter) copyOptions() *Options {
+	options := s.options
+	return &options
+}
+
+// localize writes err in the language of the options
+func (s *Sorter) localize(err error) error {
+	return messages.In(s.options.MessageLanguage(), err)
+}
+
+// localizeSeq writes the error seq yields in the language of the options
+func (s *Sorter) localizeSeq(seq iter.Seq2[string, error]) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for line, err := range seq {
+			if !yield(line, s.localize(err)) {
+				return
+			}
+		}
+	}
+}
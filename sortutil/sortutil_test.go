@@ -0,0 +1,239 @@
+package sortutil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSorterSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		input    string
+		expected string
+	}{
+		{"default options", Options{}, "cherry\napple\nbanana\n", "apple\nbanana\ncherry\n"},
+		{"numeric key", Options{Keys: []Key{{ColumnNumber: 2, Numeric: true}}}, "a 10\nb 9\n", "b 9\na 10\n"},
+		{"reverse unique", Options{Reverse: true, Unique: true}, "a\nb\nb\n", "b\na\n"},
+		{"CRLF preserved", Options{PreserveLineEndings: true}, "b\r\na\r\n", "a\r\nb\r\n"},
+		{"CSV by header name", Options{CSV: true, Keys: []Key{{ColumnName: "age", Numeric: true}}}, "name,age\nbob,30\namy,4\n", "name,age\namy,4\nbob,30\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorter, err := New(tt.options)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var output strings.Builder
+			if err := sorter.Sort(strings.NewReader(tt.input), &output); err != nil {
+				t.Fatalf("Sort() error = %v", err)
+			}
+
+			if output.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestSorterCheck(t *testing.T) {
+	sorter, err := New(Options{Numeric: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1\n2\n10\n", true},
+		{"1\n10\n2\n", false},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		sorted, err := sorter.Check(strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", tt.input, err)
+		}
+		if sorted != tt.expected {
+			t.Errorf("Check(%q) = %v, expected %v", tt.input, sorted, tt.expected)
+		}
+	}
+}
+
+func TestSorterFirstDisorder(t *testing.T) {
+	sorter, err := New(Options{Numeric: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	disorder, err := sorter.FirstDisorder(context.Background(), strings.NewReader("1\n10\n2\n3\n"))
+	if err != nil {
+		t.Fatalf("FirstDisorder() error = %v", err)
+	}
+	if disorder == nil || *disorder != (Disorder{Line: 3, Text: "2"}) {
+		t.Errorf("FirstDisorder() = %v, expected line 3", disorder)
+	}
+
+	disorder, err = sorter.FirstDisorder(context.Background(), strings.NewReader("1\n2\n"))
+	if err != nil || disorder != nil {
+		t.Errorf("FirstDisorder() = %v, %v, expected nil", disorder, err)
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{"conflicting input formats", Options{CSV: true, JSONL: true}},
+		{"column name without CSV", Options{Keys: []Key{{ColumnName: "age"}}}},
+		{"conflicting sort types", Options{Numeric: true, Month: true}},
+		{"column zero", Options{SortByColumn: true}},
+		{"field key zero", Options{Keys: []Key{{Numeric: true}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.options); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestSorterLineTooLong(t *testing.T) {
+	sorter, err := New(Options{MaxLineLength: 3})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, _, err = sorter.Lines(strings.NewReader("abc\nabcd\n"))
+	if !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("expected ErrLineTooLong, got %v", err)
+	}
+}
+
+func TestSorterLanguage(t *testing.T) {
+	sorter, err := New(Options{MaxLineLength: 3, Language: "ru"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, _, err = sorter.Lines(strings.NewReader("abc\nabcd\n"))
+	if !errors.Is(err, ErrLineTooLong) {
+		t.Fatalf("expected ErrLineTooLong, got %v", err)
+	}
+	if expected := "-:2: строка длиннее --max-line-length (максимум 3 байт)"; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+
+	_, err = New(Options{Numeric: true, Month: true, Language: "ru"})
+	if err == nil || !strings.HasPrefix(err.Error(), "несовместимые опции сортировки") {
+		t.Errorf("expected a Russian error, got %v", err)
+	}
+}
+
+func TestSorterSortContextCanceled(t *testing.T) {
+	sorter, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var output strings.Builder
+	err = sorter.SortContext(ctx, strings.NewReader("b\na\n"), &output)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// lengthType orders keys by their length in bytes; keys starting with "-" are invalid
+type lengthType struct{}
+
+func (lengthType) Parse(key string) (any, error) {
+	if strings.HasPrefix(key, "-") {
+		return nil, errors.New("negative")
+	}
+	return len(key), nil
+}
+
+func (lengthType) Compare(a, b any) int {
+	return a.(int) - b.(int)
+}
+
+func TestRegisterKeyType(t *testing.T) {
+	factory := func(string, *Options) (KeyType, error) { return lengthType{}, nil }
+	if err := RegisterKeyType("length", factory); err != nil {
+		t.Fatalf("RegisterKeyType() error = %v", err)
+	}
+	if err := RegisterKeyType("length", factory); !errors.Is(err, ErrDuplicateKeyType) {
+		t.Errorf("expected ErrDuplicateKeyType, got %v", err)
+	}
+	if err := RegisterKeyType("numeric", factory); !errors.Is(err, ErrDuplicateKeyType) {
+		t.Errorf("expected ErrDuplicateKeyType for a built-in type, got %v", err)
+	}
+
+	sorter, err := New(Options{Keys: []Key{{ColumnNumber: 2, Type: "length"}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var output strings.Builder
+	if err := sorter.Sort(strings.NewReader("a ccc\nb -\nc dd\nd b\n"), &output); err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+
+	expected := "d b\nc dd\na ccc\nb -\n"
+	if output.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, output.String())
+	}
+
+	sorted, err := sorter.Check(strings.NewReader(expected))
+	if err != nil || !sorted {
+		t.Errorf("Check() = %v, %v, expected true", sorted, err)
+	}
+
+	if _, err := New(Options{Keys: []Key{{ColumnNumber: 1, Type: "colour"}}}); !errors.Is(err, ErrUnknownKeyType) {
+		t.Errorf("expected ErrUnknownKeyType, got %v", err)
+	}
+}
+
+func TestSorterAllAndMerge(t *testing.T) {
+	sorter, err := New(Options{HumanNumeric: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var sorted []string
+	for line, err := range sorter.All(context.Background(), strings.NewReader("1M\n2K\n500\n")) {
+		if err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+		sorted = append(sorted, line)
+	}
+	if strings.Join(sorted, " ") != "500 2K 1M" {
+		t.Errorf("All() yielded %q", sorted)
+	}
+
+	var merged []string
+	for line, err := range sorter.Merge(context.Background(), strings.NewReader("1K\n1G\n"), strings.NewReader("10\n3M\n")) {
+		if err != nil {
+			t.Fatalf("Merge() error = %v", err)
+		}
+		merged = append(merged, line)
+		if len(merged) == 3 {
+			break
+		}
+	}
+	if strings.Join(merged, " ") != "10 1K 3M" {
+		t.Errorf("Merge() yielded %q", merged)
+	}
+}